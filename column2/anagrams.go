@@ -0,0 +1,57 @@
+// Command anagrams prints the anagram classes in a dictionary, Column 2 style.
+//
+// By default every class with at least two words is printed, one class per line.
+// Pass -query to just print the anagrams of a single word.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"github.com/Stantheman/pearls/helpers/anagram"
+	"os"
+	"strings"
+)
+
+func main() {
+	dict := flag.String("dict", "/usr/share/dict/words", "newline-delimited word list")
+	query := flag.String("query", "", "only print the anagrams of this word")
+	minSize := flag.Int("min", 2, "smallest class size to print")
+	mem := flag.Int("mem", 1000000, "max words to sort in memory before spilling to disk")
+	flag.Parse()
+
+	if err := run(*dict, *query, *minSize, *mem); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(dict, query string, minSize, mem int) error {
+	in, err := os.Open(dict)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	writer := bufio.NewWriter(os.Stdout)
+	defer writer.Flush()
+
+	if query != "" {
+		matches, err := anagram.Anagrams(in, query)
+		if err != nil {
+			return err
+		}
+		for _, word := range matches {
+			fmt.Fprintln(writer, word)
+		}
+		return nil
+	}
+
+	return anagram.Group(in, mem, func(class []string) error {
+		if len(class) < minSize {
+			return nil
+		}
+		_, err := fmt.Fprintln(writer, strings.Join(class, " "))
+		return err
+	})
+}
@@ -0,0 +1,280 @@
+/*
+Package anagram finds anagram classes in a dictionary.
+
+Column 2 of Programming Pearls solves this with three small programs: sign each word
+with its letters in sorted order, sort the words by signature, then squash words with
+equal signatures onto one line. This package does the same steps, falling back to an
+external merge sort when the dictionary is bigger than we'd like to hold in memory.
+*/
+package anagram
+
+import (
+	"bufio"
+	"container/heap"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Pair is a word along with its signature
+type Pair struct {
+	Sig  string
+	Word string
+}
+
+// Sign computes the canonical signature of a word: its lowercased letters in sorted order.
+// "deposit" and "posited" both sign to "deiopst".
+func Sign(word string) string {
+	letters := []rune(strings.ToLower(word))
+	sort.Slice(letters, func(i, j int) bool { return letters[i] < letters[j] })
+	return string(letters)
+}
+
+// Classes reads a newline-delimited word list and returns every anagram class, including
+// words that are only anagrams of themselves. Everything is sorted in memory.
+func Classes(in io.Reader) (classes [][]string, err error) {
+	err = Group(in, 0, func(class []string) error {
+		classes = append(classes, class)
+		return nil
+	})
+	return classes, err
+}
+
+// Group reads a newline-delimited word list and calls emit once per anagram class, in
+// signature order. Words within a class are sorted.
+//
+// If maxInMemory is > 0, at most that many words are held in memory at once. Bigger
+// lists are sorted in chunks, spilled to temp files, and merged back together.
+func Group(in io.Reader, maxInMemory int, emit func(class []string) error) error {
+	var spills []*os.File
+	defer func() {
+		for _, fh := range spills {
+			fh.Close()
+			os.Remove(fh.Name())
+		}
+	}()
+
+	pairs := make([]Pair, 0)
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		word := strings.TrimSpace(scanner.Text())
+		if word == "" {
+			continue
+		}
+		pairs = append(pairs, Pair{Sign(word), word})
+
+		if maxInMemory > 0 && len(pairs) >= maxInMemory {
+			fh, err := spill(pairs)
+			if err != nil {
+				return err
+			}
+			spills = append(spills, fh)
+			pairs = pairs[:0]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	// everything fit, skip the disk entirely
+	if len(spills) == 0 {
+		sortPairs(pairs)
+		return squash(&sliceSource{pairs: pairs}, emit)
+	}
+
+	if len(pairs) > 0 {
+		fh, err := spill(pairs)
+		if err != nil {
+			return err
+		}
+		spills = append(spills, fh)
+	}
+
+	merger, err := newMerger(spills)
+	if err != nil {
+		return err
+	}
+	return squash(merger, emit)
+}
+
+// Anagrams scans a word list for every word that is an anagram of word. The word
+// itself is included if it's in the list. No sorting is needed for a single query.
+func Anagrams(in io.Reader, word string) (matches []string, err error) {
+	sig := Sign(word)
+
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		candidate := strings.TrimSpace(scanner.Text())
+		if candidate != "" && Sign(candidate) == sig {
+			matches = append(matches, candidate)
+		}
+	}
+	return matches, scanner.Err()
+}
+
+func sortPairs(pairs []Pair) {
+	sort.Slice(pairs, func(i, j int) bool { return less(pairs[i], pairs[j]) })
+}
+
+func less(a, b Pair) bool {
+	if a.Sig != b.Sig {
+		return a.Sig < b.Sig
+	}
+	return a.Word < b.Word
+}
+
+// source hands out pairs in sorted order, returning io.EOF when empty
+type source interface {
+	next() (Pair, error)
+}
+
+// squash is the last program in the pipeline, collecting runs of equal signatures
+func squash(src source, emit func([]string) error) error {
+	var class []string
+	var sig string
+
+	for {
+		p, err := src.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if len(class) > 0 && p.Sig != sig {
+			if err := emit(class); err != nil {
+				return err
+			}
+			class = nil
+		}
+		sig = p.Sig
+		class = append(class, p.Word)
+	}
+
+	if len(class) > 0 {
+		return emit(class)
+	}
+	return nil
+}
+
+type sliceSource struct {
+	pairs []Pair
+	pos   int
+}
+
+func (s *sliceSource) next() (Pair, error) {
+	if s.pos == len(s.pairs) {
+		return Pair{}, io.EOF
+	}
+	s.pos++
+	return s.pairs[s.pos-1], nil
+}
+
+// spill sorts a chunk of pairs and writes it to a temp file as "sig\tword" lines.
+// The returned file is rewound and ready for reading; caller must close and remove it
+func spill(pairs []Pair) (*os.File, error) {
+	sortPairs(pairs)
+
+	fh, err := os.CreateTemp("", "anagram")
+	if err != nil {
+		return nil, err
+	}
+
+	writer := bufio.NewWriter(fh)
+	for _, p := range pairs {
+		if _, err := fmt.Fprintf(writer, "%v\t%v\n", p.Sig, p.Word); err != nil {
+			fh.Close()
+			os.Remove(fh.Name())
+			return nil, err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		fh.Close()
+		os.Remove(fh.Name())
+		return nil, err
+	}
+	if _, err := fh.Seek(0, 0); err != nil {
+		fh.Close()
+		os.Remove(fh.Name())
+		return nil, err
+	}
+	return fh, nil
+}
+
+// fileSource reads back a spilled chunk
+type fileSource struct {
+	scanner *bufio.Scanner
+}
+
+func (f *fileSource) next() (Pair, error) {
+	if !f.scanner.Scan() {
+		if err := f.scanner.Err(); err != nil {
+			return Pair{}, err
+		}
+		return Pair{}, io.EOF
+	}
+	sig, word, ok := strings.Cut(f.scanner.Text(), "\t")
+	if !ok {
+		return Pair{}, fmt.Errorf("malformed spill line: %q", f.scanner.Text())
+	}
+	return Pair{sig, word}, nil
+}
+
+// merger does a k-way merge over the spilled chunks with a min-heap
+type merger struct {
+	sources []source
+	heads   []Pair
+	order   []int
+}
+
+func newMerger(files []*os.File) (*merger, error) {
+	m := &merger{}
+	for _, fh := range files {
+		src := &fileSource{bufio.NewScanner(fh)}
+		p, err := src.next()
+		if err == io.EOF {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		m.sources = append(m.sources, src)
+		m.heads = append(m.heads, p)
+		m.order = append(m.order, len(m.sources)-1)
+	}
+	heap.Init(m)
+	return m, nil
+}
+
+func (m *merger) next() (Pair, error) {
+	if len(m.order) == 0 {
+		return Pair{}, io.EOF
+	}
+	i := m.order[0]
+	p := m.heads[i]
+
+	replacement, err := m.sources[i].next()
+	if err == io.EOF {
+		heap.Pop(m)
+		return p, nil
+	}
+	if err != nil {
+		return Pair{}, err
+	}
+	m.heads[i] = replacement
+	heap.Fix(m, 0)
+	return p, nil
+}
+
+// heap.Interface over the indexes of sources that still have pairs left
+func (m *merger) Len() int           { return len(m.order) }
+func (m *merger) Less(i, j int) bool { return less(m.heads[m.order[i]], m.heads[m.order[j]]) }
+func (m *merger) Swap(i, j int)      { m.order[i], m.order[j] = m.order[j], m.order[i] }
+func (m *merger) Push(x interface{}) { m.order = append(m.order, x.(int)) }
+func (m *merger) Pop() interface{} {
+	last := m.order[len(m.order)-1]
+	m.order = m.order[:len(m.order)-1]
+	return last
+}
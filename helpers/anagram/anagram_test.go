@@ -0,0 +1,106 @@
+package anagram
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var words = `pans
+pots
+opt
+snap
+stop
+tops
+deposit
+posited
+Stop
+top
+
+alone
+`
+
+var expected = [][]string{
+	{"alone"},
+	{"pans", "snap"},
+	{"deposit", "posited"},
+	{"Stop", "pots", "stop", "tops"},
+	{"opt", "top"},
+}
+
+func TestSign(t *testing.T) {
+	tests := map[string]string{
+		"deposit": "deiopst",
+		"posited": "deiopst",
+		"Stop":    "opst",
+		"":        "",
+		"héllo":   "hlloé",
+	}
+	for word, sig := range tests {
+		if res := Sign(word); res != sig {
+			t.Errorf("Sign(%q) is %q, expected %q", word, res, sig)
+		}
+	}
+}
+
+func TestClasses(t *testing.T) {
+	classes, err := Classes(strings.NewReader(words))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(classes, expected) {
+		t.Errorf("classes are %v, expected %v", classes, expected)
+	}
+}
+
+// TestGroupExternal forces the external sort with tiny chunks and makes sure it
+// agrees with the in-memory version
+func TestGroupExternal(t *testing.T) {
+	for chunk := 1; chunk < 15; chunk++ {
+		var classes [][]string
+		err := Group(strings.NewReader(words), chunk, func(class []string) error {
+			classes = append(classes, class)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(classes, expected) {
+			t.Errorf("chunk size %v: classes are %v, expected %v", chunk, classes, expected)
+		}
+	}
+}
+
+func TestAnagrams(t *testing.T) {
+	matches, err := Anagrams(strings.NewReader(words), "spot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"pots", "stop", "tops", "Stop"}; !reflect.DeepEqual(matches, want) {
+		t.Errorf("anagrams of spot are %v, expected %v", matches, want)
+	}
+
+	matches, err = Anagrams(strings.NewReader(words), "xyz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 0 {
+		t.Errorf("expected no anagrams of xyz, got %v", matches)
+	}
+}
+
+func BenchmarkClasses(b *testing.B) {
+	big := strings.Repeat(words, 1000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Classes(strings.NewReader(big))
+	}
+}
+
+func BenchmarkGroupExternal(b *testing.B) {
+	big := strings.Repeat(words, 1000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Group(strings.NewReader(big), 1000, func([]string) error { return nil })
+	}
+}
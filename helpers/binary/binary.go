@@ -1,10 +1,14 @@
+// Package binary reads and writes files of big-endian uint32s
 package binary
 
 import (
 	"encoding/binary"
+	"fmt"
+	"io"
 	"os"
 )
 
+// MakeBinaryFile writes ints to filename as raw big-endian uint32s
 func MakeBinaryFile(filename string, ints []uint32) error {
 	fh, err := os.Create(filename)
 	if err != nil {
@@ -19,13 +23,23 @@ func MakeBinaryFile(filename string, ints []uint32) error {
 	return nil
 }
 
+// ReadBinaryFile reads back every integer in a file written by MakeBinaryFile.
+// The slice is sized from the file length, which has to be a multiple of 4.
 func ReadBinaryFile(filename string) ([]uint32, error) {
 	fh, err := os.Open(filename)
 	if err != nil {
 		return nil, err
 	}
 	defer fh.Close()
-	readints := make([]uint32, 1)
+
+	info, err := fh.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size()%4 != 0 {
+		return nil, fmt.Errorf("%v is %v bytes, not a multiple of 4", filename, info.Size())
+	}
+	readints := make([]uint32, info.Size()/4)
 
 	if err := binary.Read(fh, binary.BigEndian, readints); err != nil {
 		return nil, err
@@ -33,3 +47,86 @@ func ReadBinaryFile(filename string) ([]uint32, error) {
 
 	return readints, nil
 }
+
+// DefaultBlockSize is how many integers a Reader pulls in per read if not told otherwise
+const DefaultBlockSize = 4096
+
+// Reader streams big-endian uint32s in blocks, so files too big for memory can be
+// processed a piece at a time
+type Reader struct {
+	r     io.Reader
+	buf   []byte
+	block []uint32
+	pos   int
+	err   error
+}
+
+// NewReader makes a Reader that pulls blockSize integers at a time out of r.
+// A blockSize <= 0 uses DefaultBlockSize.
+func NewReader(r io.Reader, blockSize int) *Reader {
+	if blockSize <= 0 {
+		blockSize = DefaultBlockSize
+	}
+	return &Reader{
+		r:     r,
+		buf:   make([]byte, blockSize*4),
+		block: make([]uint32, 0, blockSize),
+	}
+}
+
+// fill reads the next block from the underlying reader
+func (r *Reader) fill() error {
+	if r.err != nil {
+		return r.err
+	}
+
+	n, err := io.ReadFull(r.r, r.buf)
+	switch err {
+	case nil:
+	case io.EOF:
+		r.err = io.EOF
+		return r.err
+	case io.ErrUnexpectedEOF:
+		// a short last block is fine as long as it's whole integers
+		if n%4 != 0 {
+			r.err = fmt.Errorf("input ends with %v stray bytes, not a whole uint32", n%4)
+			return r.err
+		}
+		r.err = io.EOF
+	default:
+		r.err = err
+		return r.err
+	}
+
+	r.block = r.block[:n/4]
+	for i := range r.block {
+		r.block[i] = binary.BigEndian.Uint32(r.buf[i*4:])
+	}
+	r.pos = 0
+	return nil
+}
+
+// Block returns the integers that are buffered and not yet consumed, reading a new
+// block if needed. The slice is only valid until the next call on the Reader.
+// At the end of the input it returns io.EOF.
+func (r *Reader) Block() ([]uint32, error) {
+	if r.pos == len(r.block) {
+		if err := r.fill(); err != nil {
+			return nil, err
+		}
+	}
+	block := r.block[r.pos:]
+	r.pos = len(r.block)
+	return block, nil
+}
+
+// Next returns the next integer, or io.EOF at the end of the input
+func (r *Reader) Next() (uint32, error) {
+	if r.pos == len(r.block) {
+		if err := r.fill(); err != nil {
+			return 0, err
+		}
+	}
+	r.pos++
+	return r.block[r.pos-1], nil
+}
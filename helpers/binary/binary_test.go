@@ -2,6 +2,8 @@ package binary
 
 import (
 	"bufio"
+	"bytes"
+	"io"
 	"math/rand"
 	"os"
 	"strconv"
@@ -49,6 +51,109 @@ func TestReadingBinaryFiles(t *testing.T) {
 	}
 }
 
+// make sure everything comes back, not just the first integer
+func TestReadingBinaryFileContents(t *testing.T) {
+	if err := MakeBinaryFile(filename, ints); err != nil {
+		t.Fatal(err)
+	}
+	readints, err := ReadBinaryFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(readints) != len(ints) {
+		t.Fatalf("read %v integers, expected %v", len(readints), len(ints))
+	}
+	for i := range ints {
+		if readints[i] != ints[i] {
+			t.Fatalf("integer %v is %v, expected %v", i, readints[i], ints[i])
+		}
+	}
+}
+
+func TestReadingBinaryFileBadLength(t *testing.T) {
+	if err := os.WriteFile(filename, []byte{1, 2, 3, 4, 5}, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadBinaryFile(filename); err == nil {
+		t.Error("read a 5 byte file without complaining")
+	}
+}
+
+func TestReader(t *testing.T) {
+	if err := MakeBinaryFile(filename, ints); err != nil {
+		t.Fatal(err)
+	}
+	fh, err := os.Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fh.Close()
+
+	// a block size that doesn't divide the count, so the last block is short
+	reader := NewReader(fh, 333)
+	for i := range ints {
+		v, err := reader.Next()
+		if err != nil {
+			t.Fatalf("integer %v: %v", i, err)
+		}
+		if v != ints[i] {
+			t.Fatalf("integer %v is %v, expected %v", i, v, ints[i])
+		}
+	}
+	if _, err := reader.Next(); err != io.EOF {
+		t.Errorf("expected io.EOF at the end, got %v", err)
+	}
+}
+
+func TestReaderBlocks(t *testing.T) {
+	var buf bytes.Buffer
+	for _, v := range []uint32{1, 2, 3, 4, 5} {
+		buf.Write([]byte{0, 0, 0, byte(v)})
+	}
+
+	reader := NewReader(&buf, 2)
+	var sizes []int
+	var total uint32
+	for {
+		block, err := reader.Block()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		sizes = append(sizes, len(block))
+		for _, v := range block {
+			total += v
+		}
+	}
+	if len(sizes) != 3 || sizes[2] != 1 || total != 15 {
+		t.Errorf("blocks were %v with total %v, expected [2 2 1] and 15", sizes, total)
+	}
+}
+
+func TestReaderStrayBytes(t *testing.T) {
+	reader := NewReader(bytes.NewReader([]byte{0, 0, 0, 1, 0, 0}), 0)
+	if _, err := reader.Next(); err == nil || err == io.EOF {
+		t.Errorf("expected an error about stray bytes, got %v", err)
+	}
+}
+
+func BenchmarkReader(b *testing.B) {
+	MakeBinaryFile(filename, ints)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		fh, err := os.Open(filename)
+		if err != nil {
+			b.Fatal(err)
+		}
+		reader := NewReader(fh, 0)
+		for _, err := reader.Next(); err == nil; _, err = reader.Next() {
+		}
+		fh.Close()
+	}
+}
+
 // example control version
 func writeIntSlice() (err error) {
 
@@ -0,0 +1,262 @@
+package binary
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Format is one of the integer file formats used around the repo
+type Format int
+
+const (
+	// Text is newline-delimited decimal, what the bitmap sorts read and write
+	Text Format = iota
+	// Raw is headerless big-endian uint32s, what MakeBinaryFile writes
+	Raw
+	// Record is the self-describing format from RecordWriter
+	Record
+	// Delta is a DeltaWriter stream of varint gaps, for sorted input only
+	Delta
+)
+
+var formatNames = map[Format]string{
+	Text:   "text",
+	Raw:    "raw",
+	Record: "record",
+	Delta:  "delta",
+}
+
+func (f Format) String() string {
+	if name, ok := formatNames[f]; ok {
+		return name
+	}
+	return fmt.Sprintf("Format(%d)", int(f))
+}
+
+// ParseFormat turns a name like "text" or "record" into a Format
+func ParseFormat(name string) (Format, error) {
+	for f, n := range formatNames {
+		if strings.EqualFold(n, name) {
+			return f, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown format %q, expected text, raw, record or delta", name)
+}
+
+// Stats summarizes the values that went through a conversion
+type Stats struct {
+	Count    uint64
+	Min, Max uint64
+	// Duplicates counts values equal to the one right before them. That's every
+	// duplicate when the input is sorted.
+	Duplicates uint64
+	Sorted     bool
+	prev       uint64
+}
+
+func (s *Stats) add(v uint64) {
+	if s.Count == 0 {
+		s.Min, s.Max, s.Sorted = v, v, true
+	} else {
+		if v == s.prev {
+			s.Duplicates++
+		}
+		if v < s.prev {
+			s.Sorted = false
+		}
+		if v < s.Min {
+			s.Min = v
+		}
+		if v > s.Max {
+			s.Max = v
+		}
+	}
+	s.prev = v
+	s.Count++
+}
+
+// Converter streams integers from one Format to another, validating as it goes
+type Converter struct {
+	From, To Format
+	// Width is the element size in bits for Record output. Zero means 32.
+	Width int
+	// RequireSorted fails on the first value smaller than the one before it
+	RequireSorted bool
+}
+
+// source hands out values one at a time, returning io.EOF at the end
+type source interface {
+	Next() (uint64, error)
+}
+
+// sink takes values one at a time, and finish flushes whatever's left
+type sink interface {
+	Write(v uint64) error
+	finish() error
+}
+
+// Convert reads every value from in and writes it to out. Record output needs out
+// to be an io.WriteSeeker that can actually seek, so the header can be filled in at
+// the end; that's checked before anything is read.
+func (c Converter) Convert(in io.Reader, out io.Writer) (stats Stats, err error) {
+	src, err := c.source(in)
+	if err != nil {
+		return stats, err
+	}
+	dst, err := c.sink(out)
+	if err != nil {
+		return stats, err
+	}
+
+	for {
+		v, err := src.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return stats, fmt.Errorf("value %v: %v", stats.Count+1, err)
+		}
+		if c.RequireSorted && stats.Count > 0 && v < stats.prev {
+			return stats, fmt.Errorf("value %v: %v after %v: %v", stats.Count+1, v, stats.prev, errUnsorted)
+		}
+		if err := dst.Write(v); err != nil {
+			return stats, fmt.Errorf("value %v: %v", stats.Count+1, err)
+		}
+		stats.add(v)
+	}
+	return stats, dst.finish()
+}
+
+func (c Converter) source(in io.Reader) (source, error) {
+	switch c.From {
+	case Text:
+		return &textSource{scanner: bufio.NewScanner(in)}, nil
+	case Raw:
+		return rawSource{NewReader(in, 0)}, nil
+	case Record:
+		return NewRecordReader(in)
+	case Delta:
+		return NewDeltaReader(in), nil
+	}
+	return nil, fmt.Errorf("unknown input format %v", c.From)
+}
+
+func (c Converter) sink(out io.Writer) (sink, error) {
+	switch c.To {
+	case Text:
+		return &textSink{bufio.NewWriter(out)}, nil
+	case Raw:
+		return &rawSink{w: bufio.NewWriter(out)}, nil
+	case Record:
+		ws, ok := out.(io.WriteSeeker)
+		if !ok {
+			return nil, fmt.Errorf("record output has to be seekable")
+		}
+		// an *os.File passes the assertion even when it's a pipe, which would only
+		// fail at the header rewrite after everything went through. ask now.
+		if _, err := ws.Seek(0, io.SeekCurrent); err != nil {
+			return nil, fmt.Errorf("record output has to be seekable: %v", err)
+		}
+		width := c.Width
+		if width == 0 {
+			width = 32
+		}
+		rw, err := NewRecordWriter(ws, width, binary.BigEndian)
+		if err != nil {
+			return nil, err
+		}
+		return recordSink{rw}, nil
+	case Delta:
+		return deltaSink{NewDeltaWriter(out)}, nil
+	}
+	return nil, fmt.Errorf("unknown output format %v", c.To)
+}
+
+type textSource struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func (t *textSource) Next() (uint64, error) {
+	for t.scanner.Scan() {
+		t.line++
+		text := strings.TrimSpace(t.scanner.Text())
+		if text == "" {
+			continue
+		}
+		v, err := strconv.ParseUint(text, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("line %v: %q isn't a non-negative integer", t.line, text)
+		}
+		return v, nil
+	}
+	if err := t.scanner.Err(); err != nil {
+		return 0, err
+	}
+	return 0, io.EOF
+}
+
+type rawSource struct {
+	r *Reader
+}
+
+func (r rawSource) Next() (uint64, error) {
+	v, err := r.r.Next()
+	return uint64(v), err
+}
+
+type textSink struct {
+	w *bufio.Writer
+}
+
+func (t *textSink) Write(v uint64) error {
+	t.w.WriteString(strconv.FormatUint(v, 10))
+	return t.w.WriteByte('\n')
+}
+
+func (t *textSink) finish() error {
+	return t.w.Flush()
+}
+
+type rawSink struct {
+	w   *bufio.Writer
+	buf [4]byte
+}
+
+func (r *rawSink) Write(v uint64) error {
+	if v > math.MaxUint32 {
+		return fmt.Errorf("%v doesn't fit in a uint32", v)
+	}
+	binary.BigEndian.PutUint32(r.buf[:], uint32(v))
+	_, err := r.w.Write(r.buf[:])
+	return err
+}
+
+func (r *rawSink) finish() error {
+	return r.w.Flush()
+}
+
+type recordSink struct {
+	*RecordWriter
+}
+
+func (r recordSink) Write(v uint64) error {
+	return r.RecordWriter.Write(v)
+}
+
+func (r recordSink) finish() error {
+	return r.Close()
+}
+
+type deltaSink struct {
+	*DeltaWriter
+}
+
+func (d deltaSink) finish() error {
+	return d.Flush()
+}
@@ -0,0 +1,152 @@
+package binary
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var convertFile = "convert.bin"
+
+// TestConvertRoundTrip sends text through every format and back to text
+func TestConvertRoundTrip(t *testing.T) {
+	defer os.Remove(convertFile)
+
+	input := "1\n3\n3\n7\n\n100\n4000000000\n"
+	for _, through := range []Format{Text, Raw, Record, Delta} {
+		fh, err := os.Create(convertFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stats, err := Converter{From: Text, To: through}.Convert(strings.NewReader(input), fh)
+		fh.Close()
+		if err != nil {
+			t.Fatalf("text -> %v: %v", through, err)
+		}
+		if stats.Count != 6 || stats.Min != 1 || stats.Max != 4000000000 || stats.Duplicates != 1 || !stats.Sorted {
+			t.Errorf("text -> %v: bad stats %+v", through, stats)
+		}
+
+		fh, err = os.Open(convertFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out bytes.Buffer
+		_, err = Converter{From: through, To: Text}.Convert(fh, &out)
+		fh.Close()
+		if err != nil {
+			t.Fatalf("%v -> text: %v", through, err)
+		}
+		if expected := "1\n3\n3\n7\n100\n4000000000\n"; out.String() != expected {
+			t.Errorf("%v -> text gave %q, expected %q", through, out.String(), expected)
+		}
+	}
+}
+
+func TestConvertRawMatchesMakeBinaryFile(t *testing.T) {
+	defer os.Remove(convertFile)
+
+	var text bytes.Buffer
+	for _, v := range ints[:100] {
+		text.WriteString(strconv.FormatUint(uint64(v), 10) + "\n")
+	}
+	var raw bytes.Buffer
+	stats, err := Converter{From: Text, To: Raw}.Convert(&text, &raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Count != 100 {
+		t.Errorf("converted %v values, expected 100", stats.Count)
+	}
+
+	if err := MakeBinaryFile(convertFile, ints[:100]); err != nil {
+		t.Fatal(err)
+	}
+	expected, err := os.ReadFile(convertFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(raw.Bytes(), expected) {
+		t.Error("raw output doesn't match MakeBinaryFile")
+	}
+}
+
+func TestConvertStats(t *testing.T) {
+	stats, err := Converter{From: Text, To: Text}.Convert(strings.NewReader("5\n5\n2\n2\n9\n"), &bytes.Buffer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Count != 5 || stats.Min != 2 || stats.Max != 9 || stats.Duplicates != 2 || stats.Sorted {
+		t.Errorf("bad stats %+v", stats)
+	}
+}
+
+func TestConvertValidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		c     Converter
+		input string
+		// out is nil for cases that need a real file
+		out io.Writer
+	}{
+		{"negative text", Converter{From: Text, To: Raw}, "1\n-2\n", &bytes.Buffer{}},
+		{"garbage text", Converter{From: Text, To: Raw}, "1\nabc\n", &bytes.Buffer{}},
+		{"too big for raw", Converter{From: Text, To: Raw}, "4294967296\n", &bytes.Buffer{}},
+		{"unsorted delta", Converter{From: Text, To: Delta}, "5\n4\n", &bytes.Buffer{}},
+		{"require sorted", Converter{From: Text, To: Text, RequireSorted: true}, "5\n4\n", &bytes.Buffer{}},
+		{"stray raw bytes", Converter{From: Raw, To: Text}, "\x00\x00\x00\x01\x00", &bytes.Buffer{}},
+		{"unseekable record", Converter{From: Text, To: Record}, "1\n", &bytes.Buffer{}},
+		{"record width", Converter{From: Text, To: Record, Width: 8}, "256\n", nil},
+	}
+	for _, test := range tests {
+		var err error
+		if test.out == nil {
+			fh, ferr := os.Create(convertFile)
+			if ferr != nil {
+				t.Fatal(ferr)
+			}
+			_, err = test.c.Convert(strings.NewReader(test.input), fh)
+			fh.Close()
+			os.Remove(convertFile)
+		} else {
+			_, err = test.c.Convert(strings.NewReader(test.input), test.out)
+		}
+		if err == nil {
+			t.Errorf("%v: converted without an error", test.name)
+		}
+	}
+}
+
+// a pipe is an *os.File, so it looks seekable until it's asked to seek
+func TestConvertRecordToPipe(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	c := Converter{From: Text, To: Record}
+	stats, err := c.Convert(strings.NewReader("1\n2\n3\n"), w)
+	if err == nil {
+		t.Fatal("wrote a record file into a pipe")
+	}
+	if stats.Count != 0 {
+		t.Errorf("converted %v values before noticing the pipe", stats.Count)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, f := range []Format{Text, Raw, Record, Delta} {
+		parsed, err := ParseFormat(strings.ToUpper(f.String()))
+		if err != nil || parsed != f {
+			t.Errorf("ParseFormat(%v) is %v, %v", f, parsed, err)
+		}
+	}
+	if _, err := ParseFormat("csv"); err == nil {
+		t.Error("parsed csv")
+	}
+}
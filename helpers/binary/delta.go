@@ -0,0 +1,299 @@
+package binary
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+	"sort"
+)
+
+// Sorted output from the bitmap sorts is mostly small gaps between neighbors, so
+// storing the gaps (deltas) instead of the values, in as few bytes as each gap
+// needs, shrinks it a lot.
+
+var errUnsorted = errors.New("input isn't sorted")
+
+// Unsigned is the element types the delta encoders take
+type Unsigned interface {
+	~uint32 | ~uint64
+}
+
+// AppendDeltaVarint appends sorted to dst as a varint count followed by the varint
+// gaps between neighbors. The first value is a gap from zero.
+func AppendDeltaVarint[T Unsigned](dst []byte, sorted []T) ([]byte, error) {
+	dst = binary.AppendUvarint(dst, uint64(len(sorted)))
+	var prev uint64
+	for i, v := range sorted {
+		if i > 0 && uint64(v) < prev {
+			return nil, fmt.Errorf("%v at %v: %v", v, i, errUnsorted)
+		}
+		dst = binary.AppendUvarint(dst, uint64(v)-prev)
+		prev = uint64(v)
+	}
+	return dst, nil
+}
+
+// DecodeDeltaVarint decodes the output of AppendDeltaVarint
+func DecodeDeltaVarint(data []byte) ([]uint64, error) {
+	count, n := binary.Uvarint(data)
+	if n <= 0 {
+		return nil, errors.New("bad varint count")
+	}
+	data = data[n:]
+	// every value takes at least a byte, so the count can't be bigger than what's left
+	if count > uint64(len(data)) {
+		return nil, fmt.Errorf("count of %v but only %v bytes", count, len(data))
+	}
+
+	values := make([]uint64, count)
+	var prev uint64
+	for i := range values {
+		gap, n := binary.Uvarint(data)
+		if n <= 0 {
+			return nil, fmt.Errorf("bad varint for element %v", i)
+		}
+		data = data[n:]
+		prev += gap
+		values[i] = prev
+	}
+	return values, nil
+}
+
+// Scheme is how each block of a Compressed sequence stores its gaps
+type Scheme int
+
+const (
+	// Varint stores each gap in as many 7-bit groups as it needs
+	Varint Scheme = iota
+	// FrameOfReference bit-packs every gap in a block at the width of the biggest one,
+	// which decodes faster and wins when gaps are about the same size
+	FrameOfReference
+)
+
+// Compressed is a sorted sequence split into fixed-size blocks that are each encoded
+// on their own. The first value of every block is kept uncompressed in a skip index,
+// so finding a value is a binary search over the index and then decoding one block.
+type Compressed struct {
+	scheme    Scheme
+	blockSize int
+	count     int
+	firsts    []uint64
+	offsets   []int
+	data      []byte
+}
+
+// Compress encodes sorted in blocks of blockSize values
+func Compress[T Unsigned](sorted []T, blockSize int, scheme Scheme) (*Compressed, error) {
+	if blockSize < 1 {
+		return nil, fmt.Errorf("block size must be positive, not %v", blockSize)
+	}
+	if scheme != Varint && scheme != FrameOfReference {
+		return nil, fmt.Errorf("unknown scheme %v", scheme)
+	}
+
+	c := &Compressed{scheme: scheme, blockSize: blockSize, count: len(sorted)}
+	gaps := make([]uint64, 0, blockSize)
+
+	for start := 0; start < len(sorted); start += blockSize {
+		block := sorted[start:]
+		if len(block) > blockSize {
+			block = block[:blockSize]
+		}
+		if start > 0 && block[0] < sorted[start-1] {
+			return nil, fmt.Errorf("%v at %v: %v", block[0], start, errUnsorted)
+		}
+
+		gaps = gaps[:0]
+		for i := 1; i < len(block); i++ {
+			if block[i] < block[i-1] {
+				return nil, fmt.Errorf("%v at %v: %v", block[i], start+i, errUnsorted)
+			}
+			gaps = append(gaps, uint64(block[i]-block[i-1]))
+		}
+
+		c.firsts = append(c.firsts, uint64(block[0]))
+		c.offsets = append(c.offsets, len(c.data))
+		if scheme == Varint {
+			for _, g := range gaps {
+				c.data = binary.AppendUvarint(c.data, g)
+			}
+		} else {
+			c.data = appendFrame(c.data, gaps)
+		}
+	}
+	return c, nil
+}
+
+// appendFrame writes one byte for the bit width of the largest gap, then every gap
+// packed at that width. A width of 0 means every gap is 0 and nothing follows.
+func appendFrame(dst []byte, gaps []uint64) []byte {
+	var max uint64
+	for _, g := range gaps {
+		if g > max {
+			max = g
+		}
+	}
+	width := bits.Len64(max)
+	dst = append(dst, byte(width))
+	if width == 0 {
+		return dst
+	}
+
+	buf := bytes.NewBuffer(dst)
+	// the width is 1..64 so this can't fail, and writing to a bytes.Buffer can't either
+	p, _ := NewPackedWriter(buf, width)
+	for _, g := range gaps {
+		p.Write(g)
+	}
+	p.Flush()
+	return buf.Bytes()
+}
+
+// Len is the number of values
+func (c *Compressed) Len() int {
+	return c.count
+}
+
+// Size is the number of bytes used by the encoded blocks plus the skip index
+func (c *Compressed) Size() int {
+	return len(c.data) + len(c.firsts)*8 + len(c.offsets)*8
+}
+
+// blockLen is the number of values in block b, which is short only for the last block
+func (c *Compressed) blockLen(b int) int {
+	if b == len(c.firsts)-1 {
+		return c.count - b*c.blockSize
+	}
+	return c.blockSize
+}
+
+// decodeBlock appends the values of block b to dst
+func (c *Compressed) decodeBlock(dst []uint64, b int) []uint64 {
+	data := c.data[c.offsets[b]:]
+	v := c.firsts[b]
+	dst = append(dst, v)
+	n := c.blockLen(b)
+
+	if c.scheme == Varint {
+		for i := 1; i < n; i++ {
+			gap, used := binary.Uvarint(data)
+			data = data[used:]
+			v += gap
+			dst = append(dst, v)
+		}
+		return dst
+	}
+
+	width := int(data[0])
+	if width == 0 {
+		for i := 1; i < n; i++ {
+			dst = append(dst, v)
+		}
+		return dst
+	}
+	p, _ := NewPackedReader(bytes.NewReader(data[1:]), width)
+	for i := 1; i < n; i++ {
+		gap, _ := p.Next()
+		v += gap
+		dst = append(dst, v)
+	}
+	return dst
+}
+
+// At returns the i-th value, decoding only the block it's in
+func (c *Compressed) At(i int) uint64 {
+	if i < 0 || i >= c.count {
+		panic(fmt.Sprintf("index %v out of range [0, %v)", i, c.count))
+	}
+	b := i / c.blockSize
+	if i%c.blockSize == 0 {
+		return c.firsts[b]
+	}
+	block := c.decodeBlock(make([]uint64, 0, c.blockSize), b)
+	return block[i%c.blockSize]
+}
+
+// LowerBound returns the index of the first value >= v, or Len() if there isn't one
+func (c *Compressed) LowerBound(v uint64) int {
+	// the answer is in the last block that starts below v, or it's the start of the
+	// block after. strictly below so duplicates spanning blocks find the first copy
+	b := sort.Search(len(c.firsts), func(i int) bool { return c.firsts[i] >= v }) - 1
+	if b < 0 {
+		return 0
+	}
+	block := c.decodeBlock(make([]uint64, 0, c.blockSize), b)
+	i := sort.Search(len(block), func(i int) bool { return block[i] >= v })
+	return b*c.blockSize + i
+}
+
+// Contains reports whether v is in the sequence
+func (c *Compressed) Contains(v uint64) bool {
+	i := c.LowerBound(v)
+	return i < c.count && c.At(i) == v
+}
+
+// Decode returns every value
+func (c *Compressed) Decode() []uint64 {
+	values := make([]uint64, 0, c.count)
+	for b := range c.firsts {
+		values = c.decodeBlock(values, b)
+	}
+	return values
+}
+
+// DeltaWriter streams sorted values out as varint gaps. Unlike AppendDeltaVarint
+// there's no count up front, the stream just ends.
+type DeltaWriter struct {
+	w    *bufio.Writer
+	prev uint64
+	buf  [binary.MaxVarintLen64]byte
+}
+
+// NewDeltaWriter starts a gap stream on w. Call Flush when done.
+func NewDeltaWriter(w io.Writer) *DeltaWriter {
+	return &DeltaWriter{w: bufio.NewWriter(w)}
+}
+
+// Write appends v, which can't be smaller than the last value written
+func (d *DeltaWriter) Write(v uint64) error {
+	if v < d.prev {
+		return fmt.Errorf("%v after %v: %v", v, d.prev, errUnsorted)
+	}
+	n := binary.PutUvarint(d.buf[:], v-d.prev)
+	d.prev = v
+	_, err := d.w.Write(d.buf[:n])
+	return err
+}
+
+// Flush writes out anything buffered
+func (d *DeltaWriter) Flush() error {
+	return d.w.Flush()
+}
+
+// DeltaReader reads a gap stream written by DeltaWriter
+type DeltaReader struct {
+	r    *bufio.Reader
+	prev uint64
+}
+
+// NewDeltaReader reads gaps from r
+func NewDeltaReader(r io.Reader) *DeltaReader {
+	return &DeltaReader{r: bufio.NewReader(r)}
+}
+
+// Next returns the next value, or io.EOF at the end of the stream
+func (d *DeltaReader) Next() (uint64, error) {
+	gap, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		return 0, err
+	}
+	if d.prev+gap < d.prev {
+		return 0, errors.New("gap overflows 64 bits")
+	}
+	d.prev += gap
+	return d.prev, nil
+}
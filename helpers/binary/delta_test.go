@@ -0,0 +1,140 @@
+package binary
+
+import (
+	"fmt"
+	"github.com/Stantheman/pearls/helpers/random"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// sparseSorted picks count unique values out of [0, count*spread) and sorts them,
+// like the output of a bitmap sort over a sparse input
+func sparseSorted(count, spread int) []uint64 {
+	perm := random.GenerateUniqueRandomIntegers(count * spread)[:count]
+	sort.Ints(perm)
+	values := make([]uint64, count)
+	for i, v := range perm {
+		values[i] = uint64(v)
+	}
+	return values
+}
+
+func TestDeltaVarint(t *testing.T) {
+	for _, values := range [][]uint64{nil, {0}, {5, 5, 5}, {1, 2, 300, 1 << 40, 1<<64 - 1}, sparseSorted(1000, 10)} {
+		data, err := AppendDeltaVarint(nil, values)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := DecodeDeltaVarint(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(values) == 0 && len(decoded) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(decoded, values) {
+			t.Errorf("decoded %v, expected %v", decoded, values)
+		}
+	}
+
+	if _, err := AppendDeltaVarint(nil, []uint32{3, 2}); err == nil {
+		t.Error("encoded unsorted input")
+	}
+	if _, err := DecodeDeltaVarint([]byte{50, 1}); err == nil {
+		t.Error("decoded a count bigger than the data")
+	}
+}
+
+func TestCompressed(t *testing.T) {
+	values := sparseSorted(5000, 8)
+	// add some duplicates that straddle block boundaries
+	values = append(values, values[len(values)-1], values[len(values)-1], values[len(values)-1])
+
+	for _, scheme := range []Scheme{Varint, FrameOfReference} {
+		for _, blockSize := range []int{1, 2, 64, 127, 10000} {
+			c, err := Compress(values, blockSize, scheme)
+			if err != nil {
+				t.Fatal(err)
+			}
+			name := fmt.Sprintf("scheme %v, block %v", scheme, blockSize)
+
+			if c.Len() != len(values) {
+				t.Fatalf("%v: Len is %v, expected %v", name, c.Len(), len(values))
+			}
+			if !reflect.DeepEqual(c.Decode(), values) {
+				t.Fatalf("%v: Decode doesn't match", name)
+			}
+			for i := 0; i < len(values); i += 37 {
+				if c.At(i) != values[i] {
+					t.Fatalf("%v: At(%v) is %v, expected %v", name, i, c.At(i), values[i])
+				}
+			}
+			for v := uint64(0); v < values[len(values)-1]+2; v += 3 {
+				expected := sort.Search(len(values), func(i int) bool { return values[i] >= v })
+				if i := c.LowerBound(v); i != expected {
+					t.Fatalf("%v: LowerBound(%v) is %v, expected %v", name, v, i, expected)
+				}
+				if found := c.Contains(v); found != (expected < len(values) && values[expected] == v) {
+					t.Fatalf("%v: Contains(%v) is %v", name, v, found)
+				}
+			}
+		}
+	}
+}
+
+func TestCompressUint32(t *testing.T) {
+	values := []uint32{1, 1, 4, 9, 1 << 31}
+	c, err := Compress(values, 2, FrameOfReference)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []uint64{1, 1, 4, 9, 1 << 31}; !reflect.DeepEqual(c.Decode(), expected) {
+		t.Errorf("decoded %v, expected %v", c.Decode(), expected)
+	}
+
+	if _, err := Compress([]uint32{5, 4}, 8, Varint); err == nil {
+		t.Error("compressed unsorted input")
+	}
+	if _, err := Compress([]uint32{1, 5, 4}, 2, Varint); err == nil {
+		t.Error("compressed input unsorted across a block boundary")
+	}
+	if _, err := Compress(values, 0, Varint); err == nil {
+		t.Error("accepted a block size of 0")
+	}
+}
+
+// the compression benchmarks report bytes per value next to the 4 a raw uint32 takes
+func benchmarkCompress(b *testing.B, spread int, scheme Scheme) {
+	values := sparseSorted(100000, spread)
+	b.ResetTimer()
+	var c *Compressed
+	for i := 0; i < b.N; i++ {
+		c, _ = Compress(values, 128, scheme)
+	}
+	b.ReportMetric(float64(c.Size())/float64(len(values)), "bytes/value")
+}
+
+func BenchmarkCompressVarintDense(b *testing.B)  { benchmarkCompress(b, 2, Varint) }
+func BenchmarkCompressVarintSparse(b *testing.B) { benchmarkCompress(b, 1000, Varint) }
+func BenchmarkCompressFORDense(b *testing.B)     { benchmarkCompress(b, 2, FrameOfReference) }
+func BenchmarkCompressFORSparse(b *testing.B)    { benchmarkCompress(b, 1000, FrameOfReference) }
+
+func BenchmarkDeltaVarint(b *testing.B) {
+	values := sparseSorted(100000, 10)
+	b.ResetTimer()
+	var data []byte
+	for i := 0; i < b.N; i++ {
+		data, _ = AppendDeltaVarint(data[:0], values)
+	}
+	b.ReportMetric(float64(len(data))/float64(len(values)), "bytes/value")
+}
+
+func BenchmarkCompressedContains(b *testing.B) {
+	values := sparseSorted(100000, 10)
+	c, _ := Compress(values, 128, FrameOfReference)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.Contains(uint64(i % 1000000))
+	}
+}
@@ -0,0 +1,70 @@
+package binary
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"unsafe"
+)
+
+// Integer is every fixed-width integer type. int and uint are left out on purpose,
+// since their size depends on the machine that wrote the file.
+type Integer interface {
+	~int8 | ~int16 | ~int32 | ~int64 | ~uint8 | ~uint16 | ~uint32 | ~uint64
+}
+
+// sizeOf is the width of T in bytes
+func sizeOf[T Integer]() int {
+	var zero T
+	return int(unsafe.Sizeof(zero))
+}
+
+// Write writes values to w as fixed-width integers in the given byte order
+func Write[T Integer](w io.Writer, order binary.ByteOrder, values []T) error {
+	return binary.Write(w, order, values)
+}
+
+// Read reads count fixed-width integers from r
+func Read[T Integer](r io.Reader, order binary.ByteOrder, count int) ([]T, error) {
+	values := make([]T, count)
+	if err := binary.Read(r, order, values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// WriteFile writes values to filename with no header, the way MakeBinaryFile does
+// for uint32s. WriteFile(name, binary.BigEndian, ints) makes the same file.
+func WriteFile[T Integer](filename string, order binary.ByteOrder, values []T) error {
+	fh, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	if err := Write(fh, order, values); err != nil {
+		return err
+	}
+	return fh.Close()
+}
+
+// ReadFile reads a whole file written by WriteFile. The count comes from the
+// file length, which has to be a multiple of the element size.
+func ReadFile[T Integer](filename string, order binary.ByteOrder) ([]T, error) {
+	fh, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer fh.Close()
+
+	info, err := fh.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := int64(sizeOf[T]())
+	if info.Size()%size != 0 {
+		return nil, fmt.Errorf("%v is %v bytes, not a multiple of %v", filename, info.Size(), size)
+	}
+	return Read[T](fh, order, int(info.Size()/size))
+}
@@ -0,0 +1,78 @@
+package binary
+
+import (
+	"encoding/binary"
+	"math"
+	"os"
+	"reflect"
+	"testing"
+)
+
+var genericFile = "generic.bin"
+
+// roundTrip writes values out with WriteFile and makes sure ReadFile agrees
+func roundTrip[T Integer](t *testing.T, order binary.ByteOrder, values []T) {
+	if err := WriteFile(genericFile, order, values); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(genericFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := int64(len(values) * sizeOf[T]()); info.Size() != expected {
+		t.Errorf("%T file is %v bytes, expected %v", values, info.Size(), expected)
+	}
+
+	read, err := ReadFile[T](genericFile, order)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read, values) {
+		t.Errorf("%T: read %v, expected %v", values, read, values)
+	}
+}
+
+func TestGenericRoundTrip(t *testing.T) {
+	defer os.Remove(genericFile)
+
+	for _, order := range []binary.ByteOrder{binary.BigEndian, binary.LittleEndian} {
+		roundTrip(t, order, []uint8{0, 1, 255})
+		roundTrip(t, order, []uint16{0, 1, math.MaxUint16})
+		roundTrip(t, order, []uint32{0, 1, math.MaxUint32})
+		roundTrip(t, order, []uint64{0, 1, math.MaxUint64})
+		roundTrip(t, order, []int8{math.MinInt8, -1, 0, math.MaxInt8})
+		roundTrip(t, order, []int16{math.MinInt16, -1, 0, math.MaxInt16})
+		roundTrip(t, order, []int32{math.MinInt32, -1, 0, math.MaxInt32})
+		roundTrip(t, order, []int64{math.MinInt64, -1, 0, math.MaxInt64})
+	}
+}
+
+// the generic version has to produce the same bytes as the original
+func TestGenericMatchesMakeBinaryFile(t *testing.T) {
+	defer os.Remove(genericFile)
+
+	if err := WriteFile(genericFile, binary.BigEndian, ints); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadBinaryFile(genericFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read, ints) {
+		t.Error("WriteFile and ReadBinaryFile disagree")
+	}
+}
+
+func TestGenericBadLength(t *testing.T) {
+	defer os.Remove(genericFile)
+
+	if err := os.WriteFile(genericFile, []byte{1, 2, 3}, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadFile[int16](genericFile, binary.BigEndian); err == nil {
+		t.Error("read 3 bytes as int16s")
+	}
+	if _, err := ReadFile[uint8](genericFile, binary.BigEndian); err != nil {
+		t.Error(err)
+	}
+}
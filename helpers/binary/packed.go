@@ -0,0 +1,197 @@
+package binary
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+)
+
+// PackedWriter stores integers of any width from 1 to 64 bits back to back with no
+// padding, most significant bit first. A million 20-bit integers take 2.5MB instead
+// of the 4MB they'd need as uint32s.
+type PackedWriter struct {
+	w     *bufio.Writer
+	width uint
+	// acc holds the bits that don't make a whole byte yet, right-aligned
+	acc  uint64
+	nacc uint
+}
+
+// NewPackedWriter packs width-bit integers onto w. Call Flush when done to write
+// out the last partial byte.
+func NewPackedWriter(w io.Writer, width int) (*PackedWriter, error) {
+	if width < 1 || width > 64 {
+		return nil, fmt.Errorf("width must be between 1 and 64 bits, not %v", width)
+	}
+	return &PackedWriter{w: bufio.NewWriter(w), width: uint(width)}, nil
+}
+
+// Write packs v. It has to fit in the writer's width.
+func (p *PackedWriter) Write(v uint64) error {
+	if p.width < 64 && v>>p.width != 0 {
+		return fmt.Errorf("%v doesn't fit in %v bits", v, p.width)
+	}
+	// acc has at most 7 bits in it, so feed at most 56 at a time to keep it in 64
+	if p.width > 56 {
+		if err := p.writeBits(v>>32, p.width-32); err != nil {
+			return err
+		}
+		return p.writeBits(v&(1<<32-1), 32)
+	}
+	return p.writeBits(v, p.width)
+}
+
+func (p *PackedWriter) writeBits(v uint64, n uint) error {
+	p.acc = p.acc<<n | v
+	p.nacc += n
+	for p.nacc >= 8 {
+		p.nacc -= 8
+		if err := p.w.WriteByte(byte(p.acc >> p.nacc)); err != nil {
+			return err
+		}
+	}
+	p.acc &= 1<<p.nacc - 1
+	return nil
+}
+
+// Flush writes any leftover bits, padded with zeros to a whole byte, and flushes
+// the buffer. Nothing should be written after Flush.
+func (p *PackedWriter) Flush() error {
+	if p.nacc > 0 {
+		if err := p.w.WriteByte(byte(p.acc << (8 - p.nacc))); err != nil {
+			return err
+		}
+		p.acc, p.nacc = 0, 0
+	}
+	return p.w.Flush()
+}
+
+// PackedReader unpacks integers written by PackedWriter. The caller has to know
+// how many there are, since the padding in the last byte looks like data.
+type PackedReader struct {
+	r     *bufio.Reader
+	width uint
+	acc   uint64
+	nacc  uint
+}
+
+// NewPackedReader unpacks width-bit integers from r
+func NewPackedReader(r io.Reader, width int) (*PackedReader, error) {
+	if width < 1 || width > 64 {
+		return nil, fmt.Errorf("width must be between 1 and 64 bits, not %v", width)
+	}
+	return &PackedReader{r: bufio.NewReader(r), width: uint(width)}, nil
+}
+
+// Next unpacks the next integer
+func (p *PackedReader) Next() (uint64, error) {
+	if p.width > 56 {
+		hi, err := p.readBits(p.width - 32)
+		if err != nil {
+			return 0, err
+		}
+		lo, err := p.readBits(32)
+		if err != nil {
+			return 0, unexpected(err)
+		}
+		return hi<<32 | lo, nil
+	}
+	return p.readBits(p.width)
+}
+
+func (p *PackedReader) readBits(n uint) (uint64, error) {
+	for p.nacc < n {
+		b, err := p.r.ReadByte()
+		if err != nil {
+			// running out partway through a value is corruption, not a clean end
+			if p.nacc > 0 && err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return 0, err
+		}
+		p.acc = p.acc<<8 | uint64(b)
+		p.nacc += 8
+	}
+	p.nacc -= n
+	v := p.acc >> p.nacc & (1<<n - 1)
+	p.acc &= 1<<p.nacc - 1
+	return v, nil
+}
+
+func unexpected(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// WritePackedFile packs values into filename. The file starts with a one byte width
+// and a big-endian uint64 count so ReadPackedFile knows where the data stops.
+func WritePackedFile(filename string, width int, values []uint64) error {
+	fh, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	var header [9]byte
+	header[0] = byte(width)
+	binary.BigEndian.PutUint64(header[1:], uint64(len(values)))
+	if _, err := fh.Write(header[:]); err != nil {
+		return err
+	}
+
+	p, err := NewPackedWriter(fh, width)
+	if err != nil {
+		return err
+	}
+	for _, v := range values {
+		if err := p.Write(v); err != nil {
+			return err
+		}
+	}
+	if err := p.Flush(); err != nil {
+		return err
+	}
+	return fh.Close()
+}
+
+// ReadPackedFile reads back a file written by WritePackedFile
+func ReadPackedFile(filename string) (width int, values []uint64, err error) {
+	fh, err := os.Open(filename)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer fh.Close()
+
+	var header [9]byte
+	if _, err := io.ReadFull(fh, header[:]); err != nil {
+		return 0, nil, fmt.Errorf("short packed header: %v", err)
+	}
+	width = int(header[0])
+	count := binary.BigEndian.Uint64(header[1:])
+
+	p, err := NewPackedReader(fh, width)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	// don't trust a count the file can't possibly hold
+	info, err := fh.Stat()
+	if err != nil {
+		return 0, nil, err
+	}
+	if available := uint64(info.Size()-int64(len(header))) * 8 / uint64(width); count > available {
+		return 0, nil, fmt.Errorf("header says %v elements but the file only has room for %v", count, available)
+	}
+
+	values = make([]uint64, count)
+	for i := range values {
+		if values[i], err = p.Next(); err != nil {
+			return 0, nil, fmt.Errorf("element %v of %v: %v", i, count, unexpected(err))
+		}
+	}
+	return width, values, nil
+}
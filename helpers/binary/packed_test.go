@@ -0,0 +1,128 @@
+package binary
+
+import (
+	"bytes"
+	"math/rand"
+	"os"
+	"testing"
+)
+
+var packedFile = "packed.bin"
+
+func TestPackedWidths(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for width := 1; width <= 64; width++ {
+		values := make([]uint64, 100)
+		for i := range values {
+			values[i] = r.Uint64()
+			if width < 64 {
+				values[i] &= 1<<uint(width) - 1
+			}
+		}
+
+		var buf bytes.Buffer
+		w, err := NewPackedWriter(&buf, width)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, v := range values {
+			if err := w.Write(v); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Flush(); err != nil {
+			t.Fatal(err)
+		}
+		if expected := (len(values)*width + 7) / 8; buf.Len() != expected {
+			t.Errorf("%v bits: packed into %v bytes, expected %v", width, buf.Len(), expected)
+		}
+
+		pr, err := NewPackedReader(&buf, width)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, v := range values {
+			got, err := pr.Next()
+			if err != nil {
+				t.Fatalf("%v bits, element %v: %v", width, i, err)
+			}
+			if got != v {
+				t.Fatalf("%v bits, element %v: got %v, expected %v", width, i, got, v)
+			}
+		}
+	}
+}
+
+// TestPackedTwentyBits is the search_test setup: a million 20-bit integers should
+// take 2.5MB packed instead of 4MB
+func TestPackedTwentyBits(t *testing.T) {
+	defer os.Remove(packedFile)
+
+	const count, bits = 1048576, 20
+	r := rand.New(rand.NewSource(1))
+	values := make([]uint64, count)
+	for i := range values {
+		values[i] = uint64(r.Intn(1 << bits))
+	}
+
+	if err := WritePackedFile(packedFile, bits, values); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(packedFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := int64(9 + count*bits/8); info.Size() != expected {
+		t.Errorf("packed file is %v bytes, expected %v", info.Size(), expected)
+	}
+	t.Logf("%v 20-bit integers: %v bytes packed, %v as uint32s", count, info.Size(), count*4)
+
+	width, read, err := ReadPackedFile(packedFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if width != bits || len(read) != count {
+		t.Fatalf("read %v %v-bit values, expected %v %v-bit", len(read), width, count, bits)
+	}
+	for i := range values {
+		if read[i] != values[i] {
+			t.Fatalf("element %v is %v, expected %v", i, read[i], values[i])
+		}
+	}
+}
+
+func TestPackedErrors(t *testing.T) {
+	defer os.Remove(packedFile)
+
+	if _, err := NewPackedWriter(&bytes.Buffer{}, 0); err == nil {
+		t.Error("accepted a width of 0")
+	}
+	if _, err := NewPackedReader(&bytes.Buffer{}, 65); err == nil {
+		t.Error("accepted a width of 65")
+	}
+
+	w, _ := NewPackedWriter(&bytes.Buffer{}, 3)
+	if err := w.Write(8); err == nil {
+		t.Error("wrote 8 into 3 bits")
+	}
+
+	if err := WritePackedFile(packedFile, 20, []uint64{1, 2, 3}); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(packedFile)
+	os.WriteFile(packedFile, data[:len(data)-2], 0644)
+	if _, _, err := ReadPackedFile(packedFile); err == nil {
+		t.Error("read a truncated packed file")
+	}
+}
+
+func BenchmarkPackedWrite(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		w, _ := NewPackedWriter(&bytes.Buffer{}, 20)
+		for _, v := range ints {
+			w.Write(uint64(v) >> 12)
+		}
+		w.Flush()
+	}
+}
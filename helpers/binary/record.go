@@ -0,0 +1,395 @@
+package binary
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"os"
+)
+
+/*
+A record file is a self-describing file of fixed-width unsigned integers. It starts
+with a 36 byte header, always big-endian:
+
+	magic   [4]byte  "PRLS"
+	version uint8    currently 1
+	width   uint8    bits per element: 8, 16, 32 or 64
+	order   uint8    0 for big-endian elements, 1 for little-endian
+	flags   uint8    bit 0 set if min and max are filled in
+	count   uint64   number of elements
+	min     uint64   smallest element
+	max     uint64   largest element
+	crc     uint32   CRC-32 (IEEE) of the element bytes
+
+followed by count elements of width bits each. Files without a valid header are read
+as the legacy format MakeBinaryFile writes: headerless big-endian uint32s. That
+includes a legacy file that happens to start with 0x50524C53 ("PRLS"), as long as
+what follows isn't a valid version, width, order and flags. The one file that's
+still ambiguous is a legacy file whose first 8 bytes look exactly like a header
+(next byte 1, then 8/16/32/64, 0 or 1, 0 or 1), which gets read as a record file.
+Write new files with CreateRecordFile and it can't happen.
+*/
+const (
+	recordVersion    = 1
+	recordHeaderSize = 36
+	flagMinMax       = 1
+)
+
+var recordMagic = [4]byte{'P', 'R', 'L', 'S'}
+
+// Header describes the contents of a record file
+type Header struct {
+	Version uint8
+	// Width is the element size in bits
+	Width int
+	Order binary.ByteOrder
+	Count uint64
+	// HasMinMax is false for empty files and legacy files
+	HasMinMax bool
+	Min, Max  uint64
+	CRC       uint32
+	// Legacy is true for headerless files, where Count and CRC aren't known up front
+	Legacy bool
+}
+
+func checkWidth(width int) error {
+	switch width {
+	case 8, 16, 32, 64:
+		return nil
+	}
+	return fmt.Errorf("width must be 8, 16, 32 or 64 bits, not %v", width)
+}
+
+func (h *Header) marshal() []byte {
+	buf := make([]byte, recordHeaderSize)
+	copy(buf, recordMagic[:])
+	buf[4] = h.Version
+	buf[5] = uint8(h.Width)
+	if h.Order == binary.LittleEndian {
+		buf[6] = 1
+	}
+	if h.HasMinMax {
+		buf[7] |= flagMinMax
+	}
+	binary.BigEndian.PutUint64(buf[8:], h.Count)
+	binary.BigEndian.PutUint64(buf[16:], h.Min)
+	binary.BigEndian.PutUint64(buf[24:], h.Max)
+	binary.BigEndian.PutUint32(buf[32:], h.CRC)
+	return buf
+}
+
+func (h *Header) unmarshal(buf []byte) error {
+	h.Version = buf[4]
+	if h.Version != recordVersion {
+		return fmt.Errorf("unsupported record file version %v", h.Version)
+	}
+	h.Width = int(buf[5])
+	if err := checkWidth(h.Width); err != nil {
+		return err
+	}
+	switch buf[6] {
+	case 0:
+		h.Order = binary.BigEndian
+	case 1:
+		h.Order = binary.LittleEndian
+	default:
+		return fmt.Errorf("unknown byte order %v", buf[6])
+	}
+	if buf[7]&^flagMinMax != 0 {
+		return fmt.Errorf("unknown flags %08b", buf[7])
+	}
+	h.HasMinMax = buf[7]&flagMinMax != 0
+	h.Count = binary.BigEndian.Uint64(buf[8:])
+	h.Min = binary.BigEndian.Uint64(buf[16:])
+	h.Max = binary.BigEndian.Uint64(buf[24:])
+	h.CRC = binary.BigEndian.Uint32(buf[32:])
+	return nil
+}
+
+// putElement encodes v into buf using width bits and the given order
+func putElement(buf []byte, v uint64, width int, order binary.ByteOrder) {
+	switch width {
+	case 8:
+		buf[0] = uint8(v)
+	case 16:
+		order.PutUint16(buf, uint16(v))
+	case 32:
+		order.PutUint32(buf, uint32(v))
+	case 64:
+		order.PutUint64(buf, v)
+	}
+}
+
+func element(buf []byte, width int, order binary.ByteOrder) uint64 {
+	switch width {
+	case 8:
+		return uint64(buf[0])
+	case 16:
+		return uint64(order.Uint16(buf))
+	case 32:
+		return uint64(order.Uint32(buf))
+	}
+	return order.Uint64(buf)
+}
+
+// RecordWriter writes a record file. The header is written as a placeholder and
+// filled in by Close, once the count, min, max and CRC are known, so the
+// destination has to be seekable.
+type RecordWriter struct {
+	w      io.WriteSeeker
+	buf    *bufio.Writer
+	crc    hash.Hash32
+	header Header
+	elem   []byte
+	closer io.Closer
+}
+
+// NewRecordWriter starts a record file on w with the given element width in bits
+// and byte order
+func NewRecordWriter(w io.WriteSeeker, width int, order binary.ByteOrder) (*RecordWriter, error) {
+	if err := checkWidth(width); err != nil {
+		return nil, err
+	}
+	if order != binary.BigEndian && order != binary.LittleEndian {
+		return nil, errors.New("order must be binary.BigEndian or binary.LittleEndian")
+	}
+
+	rw := &RecordWriter{
+		w:    w,
+		buf:  bufio.NewWriter(w),
+		crc:  crc32.NewIEEE(),
+		elem: make([]byte, width/8),
+		header: Header{
+			Version: recordVersion,
+			Width:   width,
+			Order:   order,
+		},
+	}
+	if _, err := rw.buf.Write(rw.header.marshal()); err != nil {
+		return nil, err
+	}
+	return rw, nil
+}
+
+// CreateRecordFile creates filename and starts a record file in it. Close closes the file.
+func CreateRecordFile(filename string, width int, order binary.ByteOrder) (*RecordWriter, error) {
+	fh, err := os.Create(filename)
+	if err != nil {
+		return nil, err
+	}
+	rw, err := NewRecordWriter(fh, width, order)
+	if err != nil {
+		fh.Close()
+		return nil, err
+	}
+	rw.closer = fh
+	return rw, nil
+}
+
+// Write appends values to the file. Every value has to fit in the element width.
+func (rw *RecordWriter) Write(values ...uint64) error {
+	for _, v := range values {
+		if rw.header.Width < 64 && v>>uint(rw.header.Width) != 0 {
+			return fmt.Errorf("%v doesn't fit in %v bits", v, rw.header.Width)
+		}
+		putElement(rw.elem, v, rw.header.Width, rw.header.Order)
+		rw.crc.Write(rw.elem)
+		if _, err := rw.buf.Write(rw.elem); err != nil {
+			return err
+		}
+
+		h := &rw.header
+		if !h.HasMinMax || v < h.Min {
+			h.Min = v
+		}
+		if !h.HasMinMax || v > h.Max {
+			h.Max = v
+		}
+		h.HasMinMax = true
+		h.Count++
+	}
+	return nil
+}
+
+// Header returns the header as it stands so far
+func (rw *RecordWriter) Header() Header {
+	h := rw.header
+	h.CRC = rw.crc.Sum32()
+	return h
+}
+
+// Close flushes the elements and rewrites the header with the final count, min, max
+// and CRC. If the writer was made by CreateRecordFile, the file is closed too.
+func (rw *RecordWriter) Close() (err error) {
+	if rw.closer != nil {
+		defer func() {
+			if cerr := rw.closer.Close(); err == nil {
+				err = cerr
+			}
+		}()
+	}
+	if err := rw.buf.Flush(); err != nil {
+		return err
+	}
+
+	rw.header.CRC = rw.crc.Sum32()
+	end, err := rw.w.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := rw.w.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := rw.w.Write(rw.header.marshal()); err != nil {
+		return err
+	}
+	_, err = rw.w.Seek(end, io.SeekStart)
+	return err
+}
+
+// RecordReader reads a record file, or a legacy headerless file of big-endian uint32s
+type RecordReader struct {
+	r      *bufio.Reader
+	crc    hash.Hash32
+	header Header
+	elem   []byte
+	read   uint64
+	closer io.Closer
+}
+
+// NewRecordReader reads the header from r. If r doesn't start with a valid header
+// it's treated as a legacy file, and nothing is consumed.
+func NewRecordReader(r io.Reader) (*RecordReader, error) {
+	rr := &RecordReader{
+		r:   bufio.NewReader(r),
+		crc: crc32.NewIEEE(),
+	}
+
+	// peek, so a legacy file that only looks like it has a header loses nothing
+	buf, err := rr.r.Peek(recordHeaderSize)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	var h Header
+	if len(buf) == recordHeaderSize && bytes.Equal(buf[:len(recordMagic)], recordMagic[:]) && h.unmarshal(buf) == nil {
+		rr.header = h
+		rr.r.Discard(recordHeaderSize)
+	} else {
+		rr.header = Header{Width: 32, Order: binary.BigEndian, Legacy: true}
+	}
+	rr.elem = make([]byte, rr.header.Width/8)
+	return rr, nil
+}
+
+// OpenRecordFile opens filename and reads its header. For legacy files the count
+// is filled in from the file size, for record files the header's count has to
+// match the file size. Caller must Close the reader.
+func OpenRecordFile(filename string) (*RecordReader, error) {
+	fh, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	rr, err := NewRecordReader(fh)
+	if err != nil {
+		fh.Close()
+		return nil, err
+	}
+	info, err := fh.Stat()
+	if err != nil {
+		fh.Close()
+		return nil, err
+	}
+	if rr.header.Legacy {
+		if info.Size()%4 != 0 {
+			fh.Close()
+			return nil, fmt.Errorf("%v is %v bytes, not a multiple of 4", filename, info.Size())
+		}
+		rr.header.Count = uint64(info.Size() / 4)
+	} else if available := uint64(info.Size()-recordHeaderSize) / uint64(rr.header.Width/8); rr.header.Count != available {
+		// don't trust a count the file can't hold, it's about to size ReadAll's slice
+		fh.Close()
+		return nil, fmt.Errorf("header says %v elements but %v has room for %v", rr.header.Count, filename, available)
+	}
+	rr.closer = fh
+	return rr, nil
+}
+
+// Header describes the file being read
+func (rr *RecordReader) Header() Header {
+	return rr.header
+}
+
+// Next returns the next element. At the end of the file the count and CRC from
+// the header are checked, and io.EOF is returned if they match.
+func (rr *RecordReader) Next() (uint64, error) {
+	h := &rr.header
+	if !h.Legacy && rr.read == h.Count {
+		return 0, rr.finish()
+	}
+
+	n, err := io.ReadFull(rr.r, rr.elem)
+	if err == io.EOF && h.Legacy {
+		return 0, io.EOF
+	}
+	if err == io.EOF || (err == io.ErrUnexpectedEOF && !h.Legacy) {
+		return 0, fmt.Errorf("file ends after %v of %v elements", rr.read, h.Count)
+	}
+	if err == io.ErrUnexpectedEOF {
+		return 0, fmt.Errorf("input ends with %v stray bytes, not a whole uint32", n)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	rr.crc.Write(rr.elem)
+	rr.read++
+	return element(rr.elem, h.Width, h.Order), nil
+}
+
+// finish checks the CRC and that there's nothing after the last element
+func (rr *RecordReader) finish() error {
+	if sum := rr.crc.Sum32(); sum != rr.header.CRC {
+		return fmt.Errorf("CRC mismatch: header says %08x, data is %08x", rr.header.CRC, sum)
+	}
+	if _, err := rr.r.Peek(1); err != io.EOF {
+		return errors.New("trailing data after the last element")
+	}
+	return io.EOF
+}
+
+// maxPrealloc caps how much ReadAll allocates up front on the header's word. A
+// reader over a stream can't check the count against a file size, and a bad one
+// shouldn't be able to ask for petabytes before the first read fails.
+const maxPrealloc = 1 << 20
+
+// ReadAll reads every remaining element
+func (rr *RecordReader) ReadAll() ([]uint64, error) {
+	values := make([]uint64, 0)
+	if rr.header.Count > rr.read {
+		values = make([]uint64, 0, min(rr.header.Count-rr.read, maxPrealloc))
+	}
+	for {
+		v, err := rr.Next()
+		if err == io.EOF {
+			return values, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+}
+
+// Close closes the file if the reader was made by OpenRecordFile
+func (rr *RecordReader) Close() error {
+	if rr.closer != nil {
+		return rr.closer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package binary
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"testing"
+)
+
+var recordFile = "record.bin"
+
+func TestRecordRoundTrip(t *testing.T) {
+	defer os.Remove(recordFile)
+
+	orders := []binary.ByteOrder{binary.BigEndian, binary.LittleEndian}
+	for _, width := range []int{8, 16, 32, 64} {
+		for _, order := range orders {
+			values := make([]uint64, 1000)
+			for i := range values {
+				values[i] = uint64(ints[i]) * 7919
+				if width < 64 {
+					values[i] &= 1<<uint(width) - 1
+				}
+			}
+
+			rw, err := CreateRecordFile(recordFile, width, order)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := rw.Write(values...); err != nil {
+				t.Fatal(err)
+			}
+			if err := rw.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			info, err := os.Stat(recordFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expected := int64(recordHeaderSize + len(values)*width/8); info.Size() != expected {
+				t.Errorf("%v bit file is %v bytes, expected %v", width, info.Size(), expected)
+			}
+
+			rr, err := OpenRecordFile(recordFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			h := rr.Header()
+			if h.Legacy || h.Width != width || h.Order != order || h.Count != uint64(len(values)) {
+				t.Fatalf("bad header %+v", h)
+			}
+
+			min, max := values[0], values[0]
+			for _, v := range values {
+				if v < min {
+					min = v
+				}
+				if v > max {
+					max = v
+				}
+			}
+			if !h.HasMinMax || h.Min != min || h.Max != max {
+				t.Errorf("header min/max is %v/%v, expected %v/%v", h.Min, h.Max, min, max)
+			}
+
+			read, err := rr.ReadAll()
+			if err != nil {
+				t.Fatalf("%v bit %v: %v", width, order, err)
+			}
+			rr.Close()
+			for i := range values {
+				if read[i] != values[i] {
+					t.Fatalf("%v bit %v: element %v is %v, expected %v", width, order, i, read[i], values[i])
+				}
+			}
+		}
+	}
+}
+
+func TestRecordLegacy(t *testing.T) {
+	if err := MakeBinaryFile(filename, ints); err != nil {
+		t.Fatal(err)
+	}
+	rr, err := OpenRecordFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rr.Close()
+
+	h := rr.Header()
+	if !h.Legacy || h.Width != 32 || h.Count != uint64(len(ints)) {
+		t.Fatalf("bad legacy header %+v", h)
+	}
+	read, err := rr.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read) != len(ints) {
+		t.Fatalf("read %v integers, expected %v", len(read), len(ints))
+	}
+	for i := range ints {
+		if read[i] != uint64(ints[i]) {
+			t.Fatalf("integer %v is %v, expected %v", i, read[i], ints[i])
+		}
+	}
+}
+
+func TestRecordLegacyLooksLikeMagic(t *testing.T) {
+	defer os.Remove(recordFile)
+
+	// the first uint32 spells "PRLS", but what follows isn't a header
+	tests := map[string][]uint32{
+		"short":       {0x50524C53, 7},
+		"bad version": {0x50524C53, 0x02200000, 1, 2, 3, 4, 5, 6, 7, 8},
+		"bad width":   {0x50524C53, 0x01180000, 1, 2, 3, 4, 5, 6, 7, 8},
+		"bad flags":   {0x50524C53, 0x01200080, 1, 2, 3, 4, 5, 6, 7, 8},
+	}
+	for name, values := range tests {
+		if err := MakeBinaryFile(recordFile, values); err != nil {
+			t.Fatal(err)
+		}
+		rr, err := OpenRecordFile(recordFile)
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+		read, err := rr.ReadAll()
+		rr.Close()
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+		if !rr.Header().Legacy || len(read) != len(values) || read[0] != 0x50524C53 || read[1] != uint64(values[1]) {
+			t.Errorf("%v: read %x as %+v", name, read, rr.Header())
+		}
+	}
+}
+
+func TestRecordCorruption(t *testing.T) {
+	defer os.Remove(recordFile)
+
+	rw, err := CreateRecordFile(recordFile, 32, binary.BigEndian)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rw.Write(1, 2, 3, 4)
+	if err := rw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	good, err := os.ReadFile(recordFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	corrupt := map[string][]byte{
+		"flipped bit": append(append([]byte{}, good[:len(good)-1]...), good[len(good)-1]^1),
+		"truncated":   good[:len(good)-2],
+		"trailing":    append(append([]byte{}, good...), 0),
+	}
+	for name, data := range corrupt {
+		if err := os.WriteFile(recordFile, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		// a count that doesn't match the size is caught on open, the rest on read
+		rr, err := OpenRecordFile(recordFile)
+		if err != nil {
+			continue
+		}
+		if _, err := rr.ReadAll(); err == nil {
+			t.Errorf("%v: read corrupt file without an error", name)
+		}
+		rr.Close()
+	}
+}
+
+func TestRecordHugeCount(t *testing.T) {
+	defer os.Remove(recordFile)
+
+	h := Header{Version: recordVersion, Width: 64, Order: binary.BigEndian, Count: 1 << 62}
+	if err := os.WriteFile(recordFile, h.marshal(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenRecordFile(recordFile); err == nil {
+		t.Error("opened a file claiming 2^62 elements with none in it")
+	}
+
+	// a stream can't be checked against its size, ReadAll has to fail without
+	// trying to allocate the whole count first
+	rr, err := NewRecordReader(bytes.NewReader(h.marshal()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rr.ReadAll(); err == nil {
+		t.Error("read 2^62 elements out of an empty stream")
+	}
+}
+
+func TestRecordWriterValidation(t *testing.T) {
+	defer os.Remove(recordFile)
+
+	if _, err := CreateRecordFile(recordFile, 12, binary.BigEndian); err == nil {
+		t.Error("accepted a 12 bit width")
+	}
+
+	rw, err := CreateRecordFile(recordFile, 8, binary.BigEndian)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rw.Close()
+	if err := rw.Write(256); err == nil {
+		t.Error("wrote 256 into 8 bits")
+	}
+}
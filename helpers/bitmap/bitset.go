@@ -0,0 +1,109 @@
+package bitmap
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// Bitset is a fixed-size set of bits packed into 64-bit words, the same layout
+// BitSortPrimative uses inline, as a type other packages can share.
+type Bitset struct {
+	words  []uint64
+	length uint64
+}
+
+// NewBitset makes a Bitset that can hold bits 0..length-1, all cleared
+func NewBitset(length uint64) *Bitset {
+	return &Bitset{
+		words:  make([]uint64, (length+63)>>6),
+		length: length,
+	}
+}
+
+// NewBitsetFromWords wraps existing words, like ones read back from disk. words must
+// have room for length bits; bits past length are cleared.
+func NewBitsetFromWords(length uint64, words []uint64) (*Bitset, error) {
+	if uint64(len(words)) != (length+63)>>6 {
+		return nil, fmt.Errorf("%v bits needs %v words, got %v", length, (length+63)>>6, len(words))
+	}
+	b := &Bitset{words: words, length: length}
+	b.trim()
+	return b, nil
+}
+
+// Len is the number of bits in the set
+func (b *Bitset) Len() uint64 {
+	return b.length
+}
+
+// Words exposes the backing words, mostly for serializing
+func (b *Bitset) Words() []uint64 {
+	return b.words
+}
+
+// Set turns bit i on
+func (b *Bitset) Set(i uint64) {
+	b.words[i>>6] |= 1 << (i & 63)
+}
+
+// Clear turns bit i off
+func (b *Bitset) Clear(i uint64) {
+	b.words[i>>6] &^= 1 << (i & 63)
+}
+
+// Test reports whether bit i is on
+func (b *Bitset) Test(i uint64) bool {
+	return b.words[i>>6]&(1<<(i&63)) != 0
+}
+
+// Reset clears every bit
+func (b *Bitset) Reset() {
+	for i := range b.words {
+		b.words[i] = 0
+	}
+}
+
+// Count returns how many bits are on
+func (b *Bitset) Count() (count uint64) {
+	for _, w := range b.words {
+		count += uint64(bits.OnesCount64(w))
+	}
+	return count
+}
+
+// NextSet returns the first bit at or after i that's on. ok is false if there isn't one.
+func (b *Bitset) NextSet(i uint64) (next uint64, ok bool) {
+	if i >= b.length {
+		return 0, false
+	}
+	pos := i >> 6
+	// mask off the bits before i in the first word
+	w := b.words[pos] >> (i & 63)
+	if w != 0 {
+		return i + uint64(bits.TrailingZeros64(w)), true
+	}
+	for pos++; pos < uint64(len(b.words)); pos++ {
+		if b.words[pos] != 0 {
+			return pos<<6 + uint64(bits.TrailingZeros64(b.words[pos])), true
+		}
+	}
+	return 0, false
+}
+
+// Union turns on every bit that's on in other. Both sets must be the same length.
+func (b *Bitset) Union(other *Bitset) error {
+	if b.length != other.length {
+		return fmt.Errorf("can't union bitsets of length %v and %v", b.length, other.length)
+	}
+	for i, w := range other.words {
+		b.words[i] |= w
+	}
+	return nil
+}
+
+// trim clears the unused bits at the end of the last word
+func (b *Bitset) trim() {
+	if extra := b.length & 63; extra != 0 {
+		b.words[len(b.words)-1] &= 1<<extra - 1
+	}
+}
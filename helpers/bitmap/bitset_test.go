@@ -0,0 +1,81 @@
+package bitmap
+
+import (
+	"testing"
+)
+
+func TestBitset(t *testing.T) {
+	b := NewBitset(130)
+	if b.Len() != 130 || len(b.Words()) != 3 {
+		t.Fatalf("130 bits should be 3 words, got %v bits in %v words", b.Len(), len(b.Words()))
+	}
+
+	set := []uint64{0, 1, 63, 64, 100, 129}
+	for _, i := range set {
+		b.Set(i)
+	}
+	if b.Count() != uint64(len(set)) {
+		t.Errorf("count is %v, expected %v", b.Count(), len(set))
+	}
+	for _, i := range set {
+		if !b.Test(i) {
+			t.Errorf("bit %v should be on", i)
+		}
+	}
+	if b.Test(2) || b.Test(65) {
+		t.Error("unset bits are on")
+	}
+
+	// walk the set bits with NextSet
+	var seen []uint64
+	for i, ok := b.NextSet(0); ok; i, ok = b.NextSet(i + 1) {
+		seen = append(seen, i)
+	}
+	if len(seen) != len(set) {
+		t.Fatalf("NextSet saw %v, expected %v", seen, set)
+	}
+	for i := range set {
+		if seen[i] != set[i] {
+			t.Errorf("NextSet saw %v, expected %v", seen, set)
+		}
+	}
+
+	b.Clear(63)
+	if b.Test(63) {
+		t.Error("bit 63 didn't clear")
+	}
+
+	b.Reset()
+	if b.Count() != 0 {
+		t.Errorf("reset left %v bits on", b.Count())
+	}
+}
+
+func TestBitsetUnion(t *testing.T) {
+	a, b := NewBitset(100), NewBitset(100)
+	a.Set(3)
+	b.Set(70)
+	if err := a.Union(b); err != nil {
+		t.Fatal(err)
+	}
+	if !a.Test(3) || !a.Test(70) || a.Count() != 2 {
+		t.Error("union lost bits")
+	}
+	if err := a.Union(NewBitset(10)); err == nil {
+		t.Error("union of different sizes should fail")
+	}
+}
+
+func TestNewBitsetFromWords(t *testing.T) {
+	b, err := NewBitsetFromWords(66, []uint64{1, ^uint64(0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// only bits 64 and 65 of the second word are in range
+	if b.Count() != 3 {
+		t.Errorf("count is %v, expected 3", b.Count())
+	}
+	if _, err := NewBitsetFromWords(200, []uint64{1}); err == nil {
+		t.Error("accepted too few words")
+	}
+}
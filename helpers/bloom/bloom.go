@@ -0,0 +1,184 @@
+/*
+Package bloom implements Bloom filters over 64-bit keys.
+
+The bitmap package answers "have we seen this integer" exactly, but needs a bit per
+possible value. That's fine for 7 digit phone numbers and hopeless for 64-bit keys.
+A Bloom filter sets k bits per key in a much smaller bitmap and answers "maybe" or
+"definitely not", trading a tunable false positive rate for space.
+*/
+package bloom
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/Stantheman/pearls/helpers/bitmap"
+	"math"
+)
+
+// Filter is a standard Bloom filter. Keys can be added but never removed.
+type Filter struct {
+	bits *bitmap.Bitset
+	k    uint64
+}
+
+// OptimalSize returns the number of bits m and hash functions k that give a false
+// positive rate of p after n keys have been added:
+//
+//	m = -n ln(p) / (ln 2)^2
+//	k = (m / n) ln 2
+//
+// p has to be strictly between 0 and 1. At 0 the formula wants infinite bits, and
+// at 1 or more any filter will do, so both are errors rather than huge allocations.
+func OptimalSize(n uint64, p float64) (m, k uint64, err error) {
+	if !(p > 0 && p < 1) {
+		return 0, 0, fmt.Errorf("bloom: false positive rate %v isn't between 0 and 1", p)
+	}
+	if n == 0 {
+		n = 1
+	}
+	m = uint64(math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2)))
+	if m == 0 {
+		m = 1
+	}
+	k = uint64(math.Round(float64(m) / float64(n) * math.Ln2))
+	if k == 0 {
+		k = 1
+	}
+	return m, k, nil
+}
+
+// New makes a filter with m bits and k hash functions
+func New(m, k uint64) *Filter {
+	if m == 0 {
+		m = 1
+	}
+	if k == 0 {
+		k = 1
+	}
+	return &Filter{bits: bitmap.NewBitset(m), k: k}
+}
+
+// NewWithEstimates makes a filter sized for n keys at a false positive rate of p
+func NewWithEstimates(n uint64, p float64) (*Filter, error) {
+	m, k, err := OptimalSize(n, p)
+	if err != nil {
+		return nil, err
+	}
+	return New(m, k), nil
+}
+
+// Cap is the number of bits in the filter
+func (f *Filter) Cap() uint64 {
+	return f.bits.Len()
+}
+
+// K is the number of hash functions
+func (f *Filter) K() uint64 {
+	return f.k
+}
+
+// Add puts key in the filter
+func (f *Filter) Add(key uint64) {
+	h1, h2 := hashes(key)
+	m := f.bits.Len()
+	for i := uint64(0); i < f.k; i++ {
+		f.bits.Set((h1 + i*h2) % m)
+	}
+}
+
+// Test reports whether key might be in the filter. false means it definitely isn't.
+func (f *Filter) Test(key uint64) bool {
+	h1, h2 := hashes(key)
+	m := f.bits.Len()
+	for i := uint64(0); i < f.k; i++ {
+		if !f.bits.Test((h1 + i*h2) % m) {
+			return false
+		}
+	}
+	return true
+}
+
+// EstimatedFPR is the expected false positive rate with the current fill,
+// (fraction of bits set)^k
+func (f *Filter) EstimatedFPR() float64 {
+	return math.Pow(float64(f.bits.Count())/float64(f.bits.Len()), float64(f.k))
+}
+
+// Union adds every key in other to f. Both filters must have the same size and k.
+func (f *Filter) Union(other *Filter) error {
+	if f.k != other.k {
+		return fmt.Errorf("can't union filters with %v and %v hash functions", f.k, other.k)
+	}
+	return f.bits.Union(other.bits)
+}
+
+// MarshalBinary encodes the filter as m, k, then the bit words, all big-endian
+func (f *Filter) MarshalBinary() ([]byte, error) {
+	return marshal(f.bits.Len(), f.k, f.bits.Words()), nil
+}
+
+// UnmarshalBinary replaces f with a filter encoded by MarshalBinary
+func (f *Filter) UnmarshalBinary(data []byte) error {
+	m, k, words, err := unmarshal(data, func(m uint64) uint64 { return (m + 63) / 64 })
+	if err != nil {
+		return err
+	}
+	bits, err := bitmap.NewBitsetFromWords(m, words)
+	if err != nil {
+		return err
+	}
+	f.bits, f.k = bits, k
+	return nil
+}
+
+// hashes gives the two base hashes for double hashing. The i-th index is h1 + i*h2,
+// which behaves as well as k independent hashes (Kirsch and Mitzenmacher).
+// h2 is forced odd so it never gets stuck on zero.
+func hashes(key uint64) (h1, h2 uint64) {
+	return mix(key), mix(key^0x9e3779b97f4a7c15) | 1
+}
+
+// mix is the splitmix64 finalizer, which spreads every input bit over the output
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+var errShortData = errors.New("bloom: encoded filter is truncated")
+
+func marshal(m, k uint64, words []uint64) []byte {
+	data := make([]byte, 16+8*len(words))
+	binary.BigEndian.PutUint64(data[0:], m)
+	binary.BigEndian.PutUint64(data[8:], k)
+	for i, w := range words {
+		binary.BigEndian.PutUint64(data[16+8*i:], w)
+	}
+	return data
+}
+
+// unmarshal decodes the header and words. wordsFor says how many words m needs.
+func unmarshal(data []byte, wordsFor func(m uint64) uint64) (m, k uint64, words []uint64, err error) {
+	if len(data) < 16 {
+		return 0, 0, nil, errShortData
+	}
+	m = binary.BigEndian.Uint64(data[0:])
+	k = binary.BigEndian.Uint64(data[8:])
+	if m == 0 || k == 0 {
+		return 0, 0, nil, fmt.Errorf("bloom: invalid m=%v k=%v", m, k)
+	}
+
+	count := wordsFor(m)
+	if uint64(len(data)-16) != count*8 {
+		return 0, 0, nil, errShortData
+	}
+	words = make([]uint64, count)
+	for i := range words {
+		words[i] = binary.BigEndian.Uint64(data[16+8*i:])
+	}
+	return m, k, words, nil
+}
@@ -0,0 +1,296 @@
+package bloom
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+const (
+	items  = 100000
+	target = 0.01
+	seed   = 42
+)
+
+// keys returns n distinct random 64-bit keys. The seed is fixed so a failing
+// FPR measurement can be reproduced.
+func keys(r *rand.Rand, n int) []uint64 {
+	seen := make(map[uint64]bool, n)
+	list := make([]uint64, 0, n)
+	for len(list) < n {
+		k := r.Uint64()
+		if !seen[k] {
+			seen[k] = true
+			list = append(list, k)
+		}
+	}
+	return list
+}
+
+// tester is what both kinds of filter have in common
+type tester interface {
+	Add(uint64)
+	Test(uint64) bool
+}
+
+// measureFPR adds present to the filter, then counts how many of absent it claims
+func measureFPR(t *testing.T, f tester, present, absent []uint64) float64 {
+	for _, k := range present {
+		f.Add(k)
+	}
+	for _, k := range present {
+		if !f.Test(k) {
+			t.Fatalf("false negative on %v", k)
+		}
+	}
+	positives := 0
+	for _, k := range absent {
+		if f.Test(k) {
+			positives++
+		}
+	}
+	return float64(positives) / float64(len(absent))
+}
+
+func TestOptimalSize(t *testing.T) {
+	// the textbook numbers: 1% at 1M items is ~9.6 bits per item and 7 hashes
+	m, k, err := OptimalSize(1000000, 0.01)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m < 9500000 || m > 9700000 || k != 7 {
+		t.Errorf("m = %v, k = %v, expected ~9.6M and 7", m, k)
+	}
+
+	for _, p := range []float64{0, -0.1, 1, 1.5, math.NaN()} {
+		if _, _, err := OptimalSize(1000, p); err == nil {
+			t.Errorf("accepted a false positive rate of %v", p)
+		}
+		if _, err := NewWithEstimates(1000, p); err == nil {
+			t.Errorf("made a filter with a false positive rate of %v", p)
+		}
+		if _, err := NewCountingWithEstimates(1000, p); err == nil {
+			t.Errorf("made a counting filter with a false positive rate of %v", p)
+		}
+	}
+}
+
+func TestFilterFPR(t *testing.T) {
+	r := rand.New(rand.NewSource(seed))
+	all := keys(r, 2*items)
+
+	f, err := NewWithEstimates(items, target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fpr := measureFPR(t, f, all[:items], all[items:])
+	t.Logf("m=%v k=%v measured FPR %.4f, estimated %.4f", f.Cap(), f.K(), fpr, f.EstimatedFPR())
+
+	if fpr > target*1.5 {
+		t.Errorf("measured FPR %v is well over the target %v", fpr, target)
+	}
+}
+
+func TestCountingFilterFPR(t *testing.T) {
+	r := rand.New(rand.NewSource(seed))
+	all := keys(r, 2*items)
+
+	f, err := NewCountingWithEstimates(items, target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fpr := measureFPR(t, f, all[:items], all[items:])
+	t.Logf("m=%v k=%v measured FPR %.4f, estimated %.4f", f.Cap(), f.K(), fpr, f.EstimatedFPR())
+
+	if fpr > target*1.5 {
+		t.Errorf("measured FPR %v is well over the target %v", fpr, target)
+	}
+}
+
+func TestCountingFilterRemove(t *testing.T) {
+	r := rand.New(rand.NewSource(seed))
+	all := keys(r, 1000)
+
+	f, err := NewCountingWithEstimates(1000, target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range all {
+		f.Add(k)
+	}
+	// take out the first half, the second half has to survive
+	for _, k := range all[:500] {
+		if err := f.Remove(k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, k := range all[500:] {
+		if !f.Test(k) {
+			t.Fatalf("removing other keys lost %v", k)
+		}
+	}
+	removed := 0
+	for _, k := range all[:500] {
+		if !f.Test(k) {
+			removed++
+		}
+	}
+	if removed < 450 {
+		t.Errorf("only %v of 500 removed keys are gone", removed)
+	}
+
+	if err := NewCounting(100, 3).Remove(12345); err == nil {
+		t.Error("removed a key from an empty filter")
+	}
+}
+
+func TestCountingFilterSaturates(t *testing.T) {
+	f := NewCounting(64, 1)
+	for i := 0; i < 100; i++ {
+		f.Add(7)
+	}
+	// the counter is stuck at 15, so removing 100 times can't bring it back to zero
+	for i := 0; i < 100; i++ {
+		f.Remove(7)
+	}
+	if !f.Test(7) {
+		t.Error("saturated counter was decremented")
+	}
+}
+
+func TestUnion(t *testing.T) {
+	r := rand.New(rand.NewSource(seed))
+	all := keys(r, 2000)
+
+	a, b := New(20000, 5), New(20000, 5)
+	ca, cb := NewCounting(20000, 5), NewCounting(20000, 5)
+	for _, k := range all[:1000] {
+		a.Add(k)
+		ca.Add(k)
+	}
+	for _, k := range all[1000:] {
+		b.Add(k)
+		cb.Add(k)
+	}
+	if err := a.Union(b); err != nil {
+		t.Fatal(err)
+	}
+	if err := ca.Union(cb); err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range all {
+		if !a.Test(k) || !ca.Test(k) {
+			t.Fatalf("union lost %v", k)
+		}
+	}
+
+	if err := a.Union(New(20000, 4)); err == nil {
+		t.Error("union with a different k should fail")
+	}
+	if err := ca.Union(NewCounting(100, 5)); err == nil {
+		t.Error("union with a different m should fail")
+	}
+}
+
+func TestSerialization(t *testing.T) {
+	r := rand.New(rand.NewSource(seed))
+	all := keys(r, 5000)
+
+	f, err := NewWithEstimates(5000, target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewCountingWithEstimates(5000, target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range all {
+		f.Add(k)
+		c.Add(k)
+	}
+
+	data, err := f.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var g Filter
+	if err := g.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err = c.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var d CountingFilter
+	if err := d.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+
+	if g.Cap() != f.Cap() || g.K() != f.K() || d.Cap() != c.Cap() || d.K() != c.K() {
+		t.Fatal("decoded filters have the wrong shape")
+	}
+	for _, k := range all {
+		if !g.Test(k) || !d.Test(k) {
+			t.Fatalf("decoded filter lost %v", k)
+		}
+	}
+
+	if err := g.UnmarshalBinary(data[:20]); err == nil {
+		t.Error("decoded a truncated filter")
+	}
+}
+
+func TestCountingToFilter(t *testing.T) {
+	r := rand.New(rand.NewSource(seed))
+	all := keys(r, 1000)
+
+	c := NewCounting(10000, 4)
+	for _, k := range all {
+		c.Add(k)
+	}
+	f := c.Filter()
+	for _, k := range all {
+		if !f.Test(k) {
+			t.Fatalf("squashed filter lost %v", k)
+		}
+	}
+}
+
+func BenchmarkFilterAdd(b *testing.B) {
+	f, err := NewWithEstimates(uint64(b.N), target)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for i := 0; i < b.N; i++ {
+		f.Add(uint64(i))
+	}
+}
+
+func BenchmarkFilterTest(b *testing.B) {
+	f, err := NewWithEstimates(items, target)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for i := 0; i < items; i++ {
+		f.Add(uint64(i))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.Test(uint64(i))
+	}
+}
+
+func BenchmarkCountingFilterTest(b *testing.B) {
+	f, err := NewCountingWithEstimates(items, target)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for i := 0; i < items; i++ {
+		f.Add(uint64(i))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.Test(uint64(i))
+	}
+}
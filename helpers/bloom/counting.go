@@ -0,0 +1,171 @@
+package bloom
+
+import (
+	"fmt"
+	"math"
+)
+
+// counterBits is the width of each counter. 4 bits is the classic choice: with the
+// optimal k, the odds of any counter passing 15 are vanishingly small.
+const (
+	counterBits     = 4
+	counterMax      = 1<<counterBits - 1
+	countersPerWord = 64 / counterBits
+)
+
+// CountingFilter is a Bloom filter with a small counter in place of every bit, so
+// keys can be removed again. The counters are packed 16 to a word, the same trick
+// bitmap.SortNonUnique uses for counting duplicates.
+//
+// Once a counter hits 15 it sticks there, since we no longer know its real value.
+type CountingFilter struct {
+	counters []uint64
+	m        uint64
+	k        uint64
+}
+
+// NewCounting makes a counting filter with m counters and k hash functions
+func NewCounting(m, k uint64) *CountingFilter {
+	if m == 0 {
+		m = 1
+	}
+	if k == 0 {
+		k = 1
+	}
+	return &CountingFilter{
+		counters: make([]uint64, countingWords(m)),
+		m:        m,
+		k:        k,
+	}
+}
+
+// NewCountingWithEstimates makes a counting filter sized for n keys at a false
+// positive rate of p
+func NewCountingWithEstimates(n uint64, p float64) (*CountingFilter, error) {
+	m, k, err := OptimalSize(n, p)
+	if err != nil {
+		return nil, err
+	}
+	return NewCounting(m, k), nil
+}
+
+func countingWords(m uint64) uint64 {
+	return (m + countersPerWord - 1) / countersPerWord
+}
+
+// Cap is the number of counters in the filter
+func (f *CountingFilter) Cap() uint64 {
+	return f.m
+}
+
+// K is the number of hash functions
+func (f *CountingFilter) K() uint64 {
+	return f.k
+}
+
+// get reads counter i
+func (f *CountingFilter) get(i uint64) uint64 {
+	shift := (i % countersPerWord) * counterBits
+	return f.counters[i/countersPerWord] >> shift & counterMax
+}
+
+// set overwrites counter i with val
+func (f *CountingFilter) set(i, val uint64) {
+	shift := (i % countersPerWord) * counterBits
+	word := &f.counters[i/countersPerWord]
+	*word = *word&^(counterMax<<shift) | val<<shift
+}
+
+// Add puts key in the filter
+func (f *CountingFilter) Add(key uint64) {
+	h1, h2 := hashes(key)
+	for i := uint64(0); i < f.k; i++ {
+		pos := (h1 + i*h2) % f.m
+		if c := f.get(pos); c < counterMax {
+			f.set(pos, c+1)
+		}
+	}
+}
+
+// Remove takes key back out of the filter. Removing a key that was never added
+// would corrupt the filter, so if Test says key isn't present nothing is changed
+// and an error is returned.
+func (f *CountingFilter) Remove(key uint64) error {
+	if !f.Test(key) {
+		return fmt.Errorf("bloom: %v isn't in the filter", key)
+	}
+	h1, h2 := hashes(key)
+	for i := uint64(0); i < f.k; i++ {
+		pos := (h1 + i*h2) % f.m
+		// saturated counters have lost count, leave them alone
+		if c := f.get(pos); c < counterMax {
+			f.set(pos, c-1)
+		}
+	}
+	return nil
+}
+
+// Test reports whether key might be in the filter. false means it definitely isn't.
+func (f *CountingFilter) Test(key uint64) bool {
+	h1, h2 := hashes(key)
+	for i := uint64(0); i < f.k; i++ {
+		if f.get((h1+i*h2)%f.m) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// EstimatedFPR is the expected false positive rate with the current fill
+func (f *CountingFilter) EstimatedFPR() float64 {
+	nonzero := 0
+	for i := uint64(0); i < f.m; i++ {
+		if f.get(i) != 0 {
+			nonzero++
+		}
+	}
+	return math.Pow(float64(nonzero)/float64(f.m), float64(f.k))
+}
+
+// Union adds every key in other to f by summing the counters, saturating at 15.
+// Both filters must have the same size and k.
+func (f *CountingFilter) Union(other *CountingFilter) error {
+	if f.m != other.m || f.k != other.k {
+		return fmt.Errorf("can't union filters with m=%v k=%v and m=%v k=%v", f.m, f.k, other.m, other.k)
+	}
+	for i := uint64(0); i < f.m; i++ {
+		sum := f.get(i) + other.get(i)
+		if sum > counterMax {
+			sum = counterMax
+		}
+		f.set(i, sum)
+	}
+	return nil
+}
+
+// Filter squashes the counters down to a plain Filter with the same hashing, which
+// is a quarter of the size if keys no longer need to be removed
+func (f *CountingFilter) Filter() *Filter {
+	plain := New(f.m, f.k)
+	for i := uint64(0); i < f.m; i++ {
+		if f.get(i) != 0 {
+			plain.bits.Set(i)
+		}
+	}
+	return plain
+}
+
+// MarshalBinary encodes the filter as m, k, then the counter words, all big-endian
+func (f *CountingFilter) MarshalBinary() ([]byte, error) {
+	return marshal(f.m, f.k, f.counters), nil
+}
+
+// UnmarshalBinary replaces f with a filter encoded by MarshalBinary
+func (f *CountingFilter) UnmarshalBinary(data []byte) error {
+	m, k, words, err := unmarshal(data, countingWords)
+	if err != nil {
+		return err
+	}
+	f.counters, f.m, f.k = words, m, k
+	return nil
+}
@@ -0,0 +1,107 @@
+package fliptext
+
+import (
+	"fmt"
+)
+
+// Rotation is swapping two adjacent blocks, ab -> ba. The same reversal trick
+// stretches to blocks that aren't next to each other and to more than two blocks,
+// which covers most of the cut and paste an editor buffer does, without a second
+// buffer.
+
+// SwapAdjacent swaps s[i:mid] with s[mid:j] in place
+func SwapAdjacent[T any](s []T, i, mid, j int) {
+	RotateReverse(s[i:j], mid-i)
+}
+
+// SwapRanges swaps the ranges s[i:j] and s[k:l] in place, which don't have to be
+// the same length or next to each other. The stretch between them moves over to
+// make room: x a m b y becomes x b m a y. Reversing a m b gives b^r m^r a^r, then
+// reversing each piece puts them right way round.
+func SwapRanges[T any](s []T, i, j, k, l int) error {
+	if k < i {
+		i, j, k, l = k, l, i, j
+	}
+	if i < 0 || i > j || j > k || k > l || l > len(s) {
+		return fmt.Errorf("can't swap [%v:%v] and [%v:%v] in %v elements", i, j, k, l, len(s))
+	}
+	reverse(s, i, l)
+	// after the big reversal: b^r is first, then m^r, then a^r
+	b, m := l-k, k-j
+	reverse(s, i, i+b)
+	reverse(s, i+b, i+b+m)
+	reverse(s, i+b+m, l)
+	return nil
+}
+
+// ReverseBlocks reverses the order of the consecutive blocks at the front of s
+// with the given sizes, keeping each block's contents in order: abc -> cba.
+// It's problem 2.3's trick, reverse everything then each block back.
+func ReverseBlocks[T any](s []T, sizes []int) error {
+	total, err := blockTotal(sizes, len(s))
+	if err != nil {
+		return err
+	}
+	reverse(s, 0, total)
+	start := 0
+	for b := len(sizes) - 1; b >= 0; b-- {
+		reverse(s, start, start+sizes[b])
+		start += sizes[b]
+	}
+	return nil
+}
+
+// PermuteBlocks reorders the consecutive blocks at the front of s with the given
+// sizes so that block order[0] comes first, then order[1], and so on. It works
+// left to right, rotating each wanted block to the front of what's left, so it
+// does at most k rotations and needs O(k) extra memory for the bookkeeping, never
+// any for the elements.
+func PermuteBlocks[T any](s []T, sizes []int, order []int) error {
+	if _, err := blockTotal(sizes, len(s)); err != nil {
+		return err
+	}
+	if len(order) != len(sizes) {
+		return fmt.Errorf("order has %v blocks, expected %v", len(order), len(sizes))
+	}
+	seen := make([]bool, len(sizes))
+	for _, b := range order {
+		if b < 0 || b >= len(sizes) || seen[b] {
+			return fmt.Errorf("order %v isn't a permutation of 0..%v", order, len(sizes)-1)
+		}
+		seen[b] = true
+	}
+
+	// remaining holds the blocks not yet placed, in their current order
+	remaining := make([]int, len(sizes))
+	for i := range remaining {
+		remaining[i] = i
+	}
+	start := 0
+	for _, want := range order {
+		offset, at := 0, 0
+		for remaining[at] != want {
+			offset += sizes[remaining[at]]
+			at++
+		}
+		// everything before want shifts right past it
+		SwapAdjacent(s, start, start+offset, start+offset+sizes[want])
+		copy(remaining[1:at+1], remaining[:at])
+		remaining = remaining[1:]
+		start += sizes[want]
+	}
+	return nil
+}
+
+// blockTotal checks sizes fit in n elements and returns how many they cover
+func blockTotal(sizes []int, n int) (total int, err error) {
+	for _, size := range sizes {
+		if size < 0 {
+			return 0, fmt.Errorf("block size %v is negative", size)
+		}
+		total += size
+	}
+	if total > n {
+		return 0, fmt.Errorf("blocks cover %v elements, only have %v", total, n)
+	}
+	return total, nil
+}
@@ -0,0 +1,106 @@
+package fliptext
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestSwapAdjacent(t *testing.T) {
+	s := []byte("xxABCdefgyy")
+	SwapAdjacent(s, 2, 5, 9)
+	if string(s) != "xxdefgABCyy" {
+		t.Errorf("got %s", s)
+	}
+}
+
+func TestSwapRanges(t *testing.T) {
+	tests := []struct {
+		i, j, k, l int
+		expected   string
+	}{
+		{1, 3, 6, 9, "0678345129"},
+		{6, 9, 1, 3, "0678345129"}, // either order
+		{0, 2, 2, 5, "2340156789"}, // adjacent is just a rotation
+		{2, 2, 5, 7, "0156234789"}, // empty a moves b to it
+		{0, 10, 10, 10, "0123456789"},
+	}
+	for _, test := range tests {
+		s := []byte("0123456789")
+		if err := SwapRanges(s, test.i, test.j, test.k, test.l); err != nil {
+			t.Fatal(err)
+		}
+		if string(s) != test.expected {
+			t.Errorf("[%v:%v] and [%v:%v]: got %s, expected %v", test.i, test.j, test.k, test.l, s, test.expected)
+		}
+	}
+
+	s := []byte("0123456789")
+	if err := SwapRanges(s, 0, 5, 3, 8); err == nil {
+		t.Error("swapped overlapping ranges")
+	}
+	if err := SwapRanges(s, 0, 1, 8, 11); err == nil {
+		t.Error("swapped past the end")
+	}
+}
+
+func TestReverseBlocks(t *testing.T) {
+	s := []byte("aaBBBcdd!")
+	if err := ReverseBlocks(s, []int{2, 3, 1, 2}); err != nil {
+		t.Fatal(err)
+	}
+	if string(s) != "ddcBBBaa!" {
+		t.Errorf("got %s", s)
+	}
+	if err := ReverseBlocks(s, []int{5, 5}); err == nil {
+		t.Error("reversed blocks bigger than the slice")
+	}
+}
+
+// splitBlocks cuts s into pieces with the given sizes, to build the expected result
+func splitBlocks(s []int, sizes []int) (blocks [][]int) {
+	for _, size := range sizes {
+		blocks = append(blocks, s[:size])
+		s = s[size:]
+	}
+	return blocks
+}
+
+func TestPermuteBlocks(t *testing.T) {
+	r := rand.New(rand.NewSource(44))
+	for trial := 0; trial < 200; trial++ {
+		k := r.Intn(8) + 1
+		sizes := make([]int, k)
+		n := 0
+		for i := range sizes {
+			sizes[i] = r.Intn(6)
+			n += sizes[i]
+		}
+		s := make([]int, n+r.Intn(3))
+		for i := range s {
+			s[i] = i
+		}
+		order := r.Perm(k)
+
+		blocks := splitBlocks(slices.Clone(s), sizes)
+		var expected []int
+		for _, b := range order {
+			expected = append(expected, blocks[b]...)
+		}
+		expected = append(expected, s[n:]...)
+
+		if err := PermuteBlocks(s, sizes, order); err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(s, expected) {
+			t.Fatalf("sizes %v order %v: got %v, expected %v", sizes, order, s, expected)
+		}
+	}
+
+	s := []int{1, 2, 3}
+	for _, order := range [][]int{{0, 0}, {0, 2}, {1}} {
+		if err := PermuteBlocks(s, []int{1, 2}, order); err == nil {
+			t.Errorf("accepted order %v", order)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package fliptext
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+)
+
+// DefaultBufferSize is how much of a file the file rotations hold in memory at
+// once when they're given a size of 0
+const DefaultBufferSize = 1 << 20
+
+// ReadWriterAt is what rotating in place needs, *os.File is the usual one
+type ReadWriterAt interface {
+	io.ReaderAt
+	io.WriterAt
+}
+
+// RotateFile rotates the contents of filename left by shift bytes, in place, with
+// at most bufSize bytes in memory. It's the reversal trick again, done on disk: each
+// reversal swaps a chunk from the front with a chunk from the back until they meet,
+// so every byte is read and written twice over the three passes.
+//
+// If ctx is cancelled partway the file is left half rotated. A circular log can
+// simply be rotated again from a known state, anything else should use
+// RotateFileTo.
+func RotateFile(ctx context.Context, filename string, shift int64, bufSize int) error {
+	fh, err := os.OpenFile(filename, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	info, err := fh.Stat()
+	if err != nil {
+		fh.Close()
+		return err
+	}
+	if err := RotateInPlace(ctx, fh, info.Size(), shift, bufSize); err != nil {
+		fh.Close()
+		return err
+	}
+	return fh.Close()
+}
+
+// RotateInPlace rotates the first size bytes of rw left by shift bytes using at
+// most bufSize bytes of buffer. A negative shift rotates right.
+func RotateInPlace(ctx context.Context, rw ReadWriterAt, size, shift int64, bufSize int) error {
+	if size == 0 {
+		return nil
+	}
+	shift = normalize64(shift, size)
+	if shift == 0 {
+		return nil
+	}
+	buf := make([]byte, bufferSize(bufSize, size))
+	if err := reverseAt(ctx, rw, 0, shift, buf); err != nil {
+		return err
+	}
+	if err := reverseAt(ctx, rw, shift, size, buf); err != nil {
+		return err
+	}
+	return reverseAt(ctx, rw, 0, size, buf)
+}
+
+// RotateFileTo writes src rotated left by shift bytes to a new file dst, leaving
+// src alone. It's one streaming pass instead of the in place version's three. dst
+// is removed if the copy fails or is cancelled. dst can't be src, or a link to it,
+// creating it would truncate the input; use RotateFile for that.
+func RotateFileTo(ctx context.Context, src, dst string, shift int64, bufSize int) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	if dstInfo, err := os.Stat(dst); err == nil && os.SameFile(info, dstInfo) {
+		return fmt.Errorf("%v and %v are the same file, rotate it in place with RotateFile", src, dst)
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+
+	_, err = RotateCopy(ctx, out, in, info.Size(), shift, bufSize)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(dst)
+	}
+	return err
+}
+
+// RotateCopy writes the first size bytes of src to dst rotated left by shift,
+// copying bufSize bytes at a time. It returns the number of bytes written.
+func RotateCopy(ctx context.Context, dst io.Writer, src io.ReaderAt, size, shift int64, bufSize int) (written int64, err error) {
+	if size == 0 {
+		return 0, nil
+	}
+	shift = normalize64(shift, size)
+	buf := make([]byte, bufferSize(bufSize, size))
+
+	// the back part first, then the front
+	for _, part := range [][2]int64{{shift, size}, {0, shift}} {
+		for off := part[0]; off < part[1]; {
+			if err := ctx.Err(); err != nil {
+				return written, err
+			}
+			chunk := buf[:min(int64(len(buf)), part[1]-off)]
+			if _, err := src.ReadAt(chunk, off); err != nil {
+				return written, err
+			}
+			n, err := dst.Write(chunk)
+			written += int64(n)
+			if err != nil {
+				return written, err
+			}
+			off += int64(n)
+		}
+	}
+	return written, nil
+}
+
+// reverseAt reverses bytes i..j-1 of rw. Each step reads half a buffer from each
+// end, reverses both halves, and writes them back on the opposite ends.
+func reverseAt(ctx context.Context, rw ReadWriterAt, i, j int64, buf []byte) error {
+	half := int64(len(buf) / 2)
+	for j-i > 1 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		// the middle byte of an odd range stays put
+		n := min(half, (j-i)/2)
+		lo, hi := buf[:n], buf[n:2*n]
+		if _, err := rw.ReadAt(lo, i); err != nil {
+			return err
+		}
+		if _, err := rw.ReadAt(hi, j-n); err != nil {
+			return err
+		}
+		reverse(lo, 0, len(lo))
+		reverse(hi, 0, len(hi))
+		if _, err := rw.WriteAt(hi, i); err != nil {
+			return err
+		}
+		if _, err := rw.WriteAt(lo, j-n); err != nil {
+			return err
+		}
+		i, j = i+n, j-n
+	}
+	return nil
+}
+
+// bufferSize picks the buffer to allocate: the default if asked, at least 2 bytes
+// so reverseAt can make progress, and never more than the file needs
+func bufferSize(bufSize int, size int64) int64 {
+	if bufSize <= 0 {
+		bufSize = DefaultBufferSize
+	}
+	return max(2, min(int64(bufSize), size))
+}
+
+// normalize64 is normalize for file offsets
+func normalize64(shift, n int64) int64 {
+	shift %= n
+	if shift < 0 {
+		shift += n
+	}
+	return shift
+}
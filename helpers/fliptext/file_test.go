@@ -0,0 +1,137 @@
+package fliptext
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"os"
+	"testing"
+)
+
+const (
+	rotateFile   = "rotate.dat"
+	rotatedFile  = "rotated.dat"
+	rotateLength = 10007 // prime, so most shifts make odd sized ranges
+)
+
+// writeRotateFile fills rotateFile with random bytes and returns them
+func writeRotateFile(t *testing.T) []byte {
+	data := make([]byte, rotateLength)
+	rand.New(rand.NewSource(45)).Read(data)
+	if err := os.WriteFile(rotateFile, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestRotateFile(t *testing.T) {
+	defer os.Remove(rotateFile)
+	// buffers smaller than, near, and bigger than the file
+	for _, bufSize := range []int{0, 1, 2, 7, 64, 4096, rotateLength, 1 << 16} {
+		for _, shift := range []int64{0, 1, 100, rotateLength / 2, rotateLength - 1, -37, 3*rotateLength + 5} {
+			data := writeRotateFile(t)
+			if err := RotateFile(context.Background(), rotateFile, shift, bufSize); err != nil {
+				t.Fatal(err)
+			}
+			got, err := os.ReadFile(rotateFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, RotateReverse(data, int(shift))) {
+				t.Fatalf("buffer %v shift %v: file isn't rotated", bufSize, shift)
+			}
+		}
+	}
+}
+
+func TestRotateFileTo(t *testing.T) {
+	defer os.Remove(rotateFile)
+	defer os.Remove(rotatedFile)
+	data := writeRotateFile(t)
+
+	if err := RotateFileTo(context.Background(), rotateFile, rotatedFile, -1000, 333); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(rotatedFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := os.ReadFile(rotateFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(src, data) {
+		t.Error("the source file changed")
+	}
+	if !bytes.Equal(got, RotateReverse(data, -1000)) {
+		t.Error("new file isn't rotated")
+	}
+}
+
+func TestRotateFileToItself(t *testing.T) {
+	defer os.Remove(rotateFile)
+	defer os.Remove(rotatedFile)
+	data := writeRotateFile(t)
+
+	if err := RotateFileTo(context.Background(), rotateFile, rotateFile, 10, 0); err == nil {
+		t.Error("rotated a file onto itself")
+	}
+	// a hard link is the same file under another name
+	if err := os.Link(rotateFile, rotatedFile); err != nil {
+		t.Fatal(err)
+	}
+	if err := RotateFileTo(context.Background(), rotateFile, rotatedFile, 10, 0); err == nil {
+		t.Error("rotated a file onto a link to itself")
+	}
+
+	got, err := os.ReadFile(rotateFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Error("the source file was damaged")
+	}
+}
+
+func TestRotateEmptyFile(t *testing.T) {
+	defer os.Remove(rotateFile)
+	if err := os.WriteFile(rotateFile, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := RotateFile(context.Background(), rotateFile, 5, 0); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRotateFileCancelled(t *testing.T) {
+	defer os.Remove(rotateFile)
+	defer os.Remove(rotatedFile)
+	writeRotateFile(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := RotateFile(ctx, rotateFile, 10, 64); err != context.Canceled {
+		t.Errorf("in place: expected context.Canceled, got %v", err)
+	}
+	if err := RotateFileTo(ctx, rotateFile, rotatedFile, 10, 64); err != context.Canceled {
+		t.Errorf("copy: expected context.Canceled, got %v", err)
+	}
+	if _, err := os.Stat(rotatedFile); !os.IsNotExist(err) {
+		t.Error("cancelled copy left its output behind")
+	}
+}
+
+func BenchmarkRotateFile(b *testing.B) {
+	data := make([]byte, 16<<20)
+	if err := os.WriteFile(rotateFile, data, 0644); err != nil {
+		b.Fatal(err)
+	}
+	defer os.Remove(rotateFile)
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := RotateFile(context.Background(), rotateFile, int64(len(data)/3), 0); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -1,28 +1,34 @@
 package fliptext
 
 import (
-	"bytes"
 	"github.com/Stantheman/pearls/helpers/gcd"
-	// "math/big"
-	// "os"
+	"strings"
 )
 
-var buffer bytes.Buffer
-
 /* Challenge B from Programming Pearls is to rotate a 1D array
 of N characters left M positions. So the string ABCDEFGH with M=3
 should become DEFGHABC*/
 
+// Every rotation here is safe to call from many goroutines at once, since none of
+// them share state, and rotating an empty string or slice gives it back unchanged.
+// RotateDelicateBytes rotates its argument in place, so goroutines shouldn't share
+// the slice they pass it. RotateReverseBytes works on a copy and leaves its input alone.
+
 // FlipTextNaive takes the cue from the introduction,
 // "Simple code uses an N-element intermediate vector to do the job
 // in N steps"
 func RotateTextNaive(t string, shift int) string {
+	if len(t) == 0 {
+		return t
+	}
 	var pointer int = shift % len(t)
 	if pointer == 0 {
 		return t
 	}
-	// faster concatenation, same idea as intermediate += string(t[pointer])
-	buffer.Truncate(0)
+	// faster concatenation, same idea as intermediate += string(t[pointer]).
+	// this used to be a package-level buffer, which two goroutines would scribble over
+	var buffer strings.Builder
+	buffer.Grow(len(t))
 
 	for i := 0; i < len(t); i++ {
 		if pointer == len(t) {
@@ -35,6 +41,9 @@ func RotateTextNaive(t string, shift int) string {
 }
 
 func RotateTextNaiveLessMem(t string, shift int) string {
+	if len(t) == 0 {
+		return t
+	}
 	shift = shift % len(t)
 	if shift == 0 {
 		return t
@@ -59,8 +68,10 @@ func RotateTextTimes(t string, times int) string {
 // X[2I + 1] to X[I + 1]. if this hasn't moved every entry, try again
 // with 2
 func RotateDelicate(t string, shift int) string {
-	temp := t[0]
 	length := len(t)
+	if length == 0 {
+		return t
+	}
 	shift = shift % length
 	if shift == 0 {
 		return t
@@ -74,10 +85,9 @@ func RotateDelicate(t string, shift int) string {
 		for i := shift + perm; ; i = (i + shift) % length {
 			var send int = (length + i - shift) % length
 
-			if i != perm {
-				bytes[send] = t[i]
-			} else {
-				bytes[send] = temp
+			// t never changes, so the cycle can close by reading t[perm] directly
+			bytes[send] = t[i]
+			if i == perm {
 				break
 			}
 
@@ -87,8 +97,10 @@ func RotateDelicate(t string, shift int) string {
 	return string(bytes)
 }
 func RotateDelicateBytes(bytes []byte, shift int) []byte {
-	temp := bytes[0]
 	length := len(bytes)
+	if length == 0 {
+		return bytes
+	}
 	shift = shift % length
 	if shift == 0 {
 		return bytes
@@ -96,6 +108,8 @@ func RotateDelicateBytes(bytes []byte, shift int) []byte {
 	perms := int(gcd.EuclidGCD(uint(shift), uint(length)))
 
 	for perm := 0; perm < perms; perm++ {
+		// each cycle starts somewhere new, so hold on to that cycle's first byte
+		temp := bytes[perm]
 		for i := shift + perm; ; i = (i + shift) % length {
 			var send int = (length + i - shift) % length
 
@@ -125,6 +139,9 @@ func RotateDelicateBytes(bytes []byte, shift int) []byte {
 // the golang naive one
 func RotateReverseBytes(bytes []byte, shift int) []byte {
 	length := len(bytes)
+	if length == 0 {
+		return bytes
+	}
 	shift = shift % length
 	if shift == 0 {
 		return bytes
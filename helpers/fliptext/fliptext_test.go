@@ -1,6 +1,7 @@
 package fliptext
 
 import (
+	"sync"
 	"testing"
 )
 
@@ -72,3 +73,63 @@ func BenchmarkRotateReverseBytes(b *testing.B) {
 		RotateReverseBytes(bytestring, 3)
 	}
 }
+
+// rotations lets the tests run every version the same way
+var rotations = map[string]func(string, int) string{
+	"Naive":        RotateTextNaive,
+	"NaiveLessMem": RotateTextNaiveLessMem,
+	"Times":        RotateTextTimes,
+	"Delicate":     RotateDelicate,
+	"DelicateBytes": func(t string, shift int) string {
+		return string(RotateDelicateBytes([]byte(t), shift))
+	},
+	"ReverseBytes": func(t string, shift int) string {
+		return string(RotateReverseBytes([]byte(t), shift))
+	},
+}
+
+func TestRotations(t *testing.T) {
+	// 12 has plenty of divisors, so the juggling versions need more than one cycle
+	const text = "abcdefghijkl"
+	for name, rotate := range rotations {
+		for shift := 0; shift < len(text)*2; shift++ {
+			expected := text[shift%len(text):] + text[:shift%len(text)]
+			if res := rotate(text, shift); res != expected {
+				t.Errorf("%v by %v: got %v, expected %v", name, shift, res, expected)
+			}
+		}
+	}
+}
+
+func TestRotateEmpty(t *testing.T) {
+	for name, rotate := range rotations {
+		for _, shift := range []int{0, 1, 5} {
+			if res := rotate("", shift); res != "" {
+				t.Errorf("%v of an empty string by %v gave %q", name, shift, res)
+			}
+		}
+	}
+}
+
+// TestRotateConcurrent rotates from lots of goroutines at once. Run it with -race,
+// RotateTextNaive used to share one buffer between every caller.
+func TestRotateConcurrent(t *testing.T) {
+	const goroutines = 64
+	for name, rotate := range rotations {
+		var wg sync.WaitGroup
+		for g := 0; g < goroutines; g++ {
+			wg.Add(1)
+			go func(shift int) {
+				defer wg.Done()
+				expected := teststring[shift:] + teststring[:shift]
+				for i := 0; i < 20; i++ {
+					if res := rotate(teststring, shift); res != expected {
+						t.Errorf("%v by %v came back mangled", name, shift)
+						return
+					}
+				}
+			}(g)
+		}
+		wg.Wait()
+	}
+}
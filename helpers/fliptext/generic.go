@@ -0,0 +1,147 @@
+package fliptext
+
+import (
+	"github.com/Stantheman/pearls/helpers/gcd"
+)
+
+// The rotations below work on any slice type, in place, with O(1) extra memory.
+// A positive shift rotates left like the rest of the package (ABCDEFGH by 3 is
+// DEFGHABC), a negative shift rotates right. They all return s for chaining.
+
+// Rotate rotates s left by shift using the reversal trick, which is the one to
+// reach for: short, cache friendly and hard to get wrong
+func Rotate[T any](s []T, shift int) []T {
+	return RotateReverse(s, shift)
+}
+
+// normalize turns any shift, negative or bigger than n, into 0..n-1 left positions
+func normalize(shift, n int) int {
+	shift %= n
+	if shift < 0 {
+		shift += n
+	}
+	return shift
+}
+
+// reverse flips s[i:j] in place
+func reverse[T any](s []T, i, j int) {
+	for j--; i < j; i, j = i+1, j-1 {
+		s[i], s[j] = s[j], s[i]
+	}
+}
+
+// RotateReverse is the "aha" from the book: reverse a, reverse b, then reverse
+// the whole thing. (a^r b^r)^r = ba
+func RotateReverse[T any](s []T, shift int) []T {
+	if len(s) == 0 {
+		return s
+	}
+	shift = normalize(shift, len(s))
+	if shift == 0 {
+		return s
+	}
+	reverse(s, 0, shift)
+	reverse(s, shift, len(s))
+	reverse(s, 0, len(s))
+	return s
+}
+
+// RotateJuggle is RotateDelicateBytes for any type: gcd(shift, n) cycles, each
+// moving every shift-th element into place with one temporary
+func RotateJuggle[T any](s []T, shift int) []T {
+	length := len(s)
+	if length == 0 {
+		return s
+	}
+	shift = normalize(shift, length)
+	if shift == 0 {
+		return s
+	}
+	perms := int(gcd.EuclidGCD(uint(shift), uint(length)))
+
+	for perm := 0; perm < perms; perm++ {
+		temp := s[perm]
+		j := perm
+		for {
+			k := j + shift
+			if k >= length {
+				k -= length
+			}
+			if k == perm {
+				break
+			}
+			s[j] = s[k]
+			j = k
+		}
+		s[j] = temp
+	}
+	return s
+}
+
+// RotateBlockSwap is the Gries-Mills block swap. If a is shorter than b, split b
+// into b_l b_r with b_r as long as a, swap a with b_r to get b_r b_l a, and a is
+// done; recurse on b_r b_l. Same idea the other way round. Written as a loop, since
+// each step just shrinks the window.
+func RotateBlockSwap[T any](s []T, shift int) []T {
+	if len(s) == 0 {
+		return s
+	}
+	shift = normalize(shift, len(s))
+	if shift == 0 {
+		return s
+	}
+	// the window still to rotate is s[start:start+a+b], with a elements in front
+	start, a, b := 0, shift, len(s)-shift
+	for a != b {
+		if a < b {
+			swapBlocks(s, start, start+b, a)
+			b -= a
+		} else {
+			swapBlocks(s, start, start+a, b)
+			start += b
+			a -= b
+		}
+	}
+	swapBlocks(s, start, start+a, a)
+	return s
+}
+
+// swapBlocks swaps the n elements at i with the n elements at j
+func swapBlocks[T any](s []T, i, j, n int) {
+	for k := 0; k < n; k++ {
+		s[i+k], s[j+k] = s[j+k], s[i+k]
+	}
+}
+
+// RotateCycleLeader follows the same cycles as RotateJuggle but doesn't need the
+// gcd: it counts how many elements have landed and starts the next cycle one
+// over until all n have. Costs a counter instead of a division loop up front.
+func RotateCycleLeader[T any](s []T, shift int) []T {
+	length := len(s)
+	if length == 0 {
+		return s
+	}
+	shift = normalize(shift, length)
+	if shift == 0 {
+		return s
+	}
+	moved := 0
+	for leader := 0; moved < length; leader++ {
+		temp := s[leader]
+		j := leader
+		for {
+			k := j + shift
+			if k >= length {
+				k -= length
+			}
+			moved++
+			if k == leader {
+				break
+			}
+			s[j] = s[k]
+			j = k
+		}
+		s[j] = temp
+	}
+	return s
+}
@@ -0,0 +1,103 @@
+package fliptext
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+// generics can't be stored in a map until they're instantiated, so each test
+// instantiates the whole set for its element type
+func rotators[T any]() map[string]func([]T, int) []T {
+	return map[string]func([]T, int) []T{
+		"Reverse":     RotateReverse[T],
+		"Juggle":      RotateJuggle[T],
+		"BlockSwap":   RotateBlockSwap[T],
+		"CycleLeader": RotateCycleLeader[T],
+	}
+}
+
+// wide is a 32 byte element, to see how the algorithms do when moves aren't cheap
+type wide [4]int64
+
+// expected rotates the slow, obvious way with a second slice
+func expected[T any](s []T, shift int) []T {
+	out := make([]T, 0, len(s))
+	if len(s) == 0 {
+		return out
+	}
+	shift = normalize(shift, len(s))
+	return append(append(out, s[shift:]...), s[:shift]...)
+}
+
+func testRotators[T comparable](t *testing.T, gen func(i int) T) {
+	for name, rotate := range rotators[T]() {
+		for _, n := range []int{0, 1, 2, 7, 12, 60, 97} {
+			orig := build(n, gen)
+			for shift := -2 * n; shift <= 2*n+1; shift++ {
+				s := slices.Clone(orig)
+				want := expected(orig, shift)
+				if got := rotate(s, shift); !slices.Equal(got, want) || !slices.Equal(s, want) {
+					t.Fatalf("%v on %v elements by %v: got %v, expected %v", name, n, shift, s, want)
+				}
+			}
+		}
+	}
+}
+
+// build makes n elements with gen
+func build[T any](n int, gen func(i int) T) []T {
+	s := make([]T, n)
+	for i := range s {
+		s[i] = gen(i)
+	}
+	return s
+}
+
+func TestRotateGenericBytes(t *testing.T) {
+	testRotators(t, func(i int) byte { return byte(i) })
+}
+
+func TestRotateGenericInts(t *testing.T) {
+	testRotators(t, func(i int) int64 { return int64(i) })
+}
+
+func TestRotateGenericWide(t *testing.T) {
+	testRotators(t, func(i int) wide { return wide{int64(i), -int64(i)} })
+}
+
+func TestRotateGenericStrings(t *testing.T) {
+	testRotators(t, func(i int) string { return fmt.Sprint(i) })
+}
+
+func TestRotateDefault(t *testing.T) {
+	s := []byte("ABCDEFGH")
+	if got := string(Rotate(s, 3)); got != "DEFGHABC" {
+		t.Errorf("left by 3 gave %v", got)
+	}
+	if got := string(Rotate(s, -3)); got != "ABCDEFGH" {
+		t.Errorf("right by 3 didn't undo it, gave %v", got)
+	}
+}
+
+func benchmarkRotators[T any](b *testing.B, size string, n int) {
+	s := make([]T, n)
+	for name, rotate := range rotators[T]() {
+		// a shift sharing a big factor with n, and one that's coprime
+		for _, shift := range []int{n / 4, n/3 + 1} {
+			b.Run(fmt.Sprintf("%v/%v/n=%v/shift=%v", name, size, n, shift), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					rotate(s, shift)
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkRotateGeneric(b *testing.B) {
+	for _, n := range []int{1 << 10, 1 << 20} {
+		benchmarkRotators[byte](b, "1B", n)
+		benchmarkRotators[int64](b, "8B", n)
+		benchmarkRotators[wide](b, "32B", n)
+	}
+}
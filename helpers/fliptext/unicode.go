@@ -0,0 +1,117 @@
+package fliptext
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Everything else in this package rotates bytes, which is fine for ASCII and
+// breaks UTF-8: "héllo" by 1 leaves half of the é at the end. The versions here
+// count the shift in runes or in user-perceived characters (grapheme clusters)
+// instead.
+//
+// Rotating by whole characters is still just rotating bytes, as long as the cut
+// lands between characters. So each one finds the byte offset of the cut and
+// hands it to the in-place reversal, and the []byte forms need no extra memory.
+// A negative shift rotates right, same as Rotate. Invalid UTF-8 bytes count as
+// one rune each, like utf8.DecodeRune treats them.
+
+// RotateRunes rotates t left by shift runes
+func RotateRunes(t string, shift int) string {
+	return string(RotateRunesBytes([]byte(t), shift))
+}
+
+// RotateRunesBytes rotates the UTF-8 in b left by shift runes, in place
+func RotateRunesBytes(b []byte, shift int) []byte {
+	return rotateUnits(b, shift, func(b []byte) int {
+		_, size := utf8.DecodeRune(b)
+		return size
+	})
+}
+
+// RotateGraphemes rotates t left by shift characters, where a character is a
+// grapheme cluster: e + a combining accent, a flag, or a family emoji glued
+// together with zero width joiners all move as one
+func RotateGraphemes(t string, shift int) string {
+	return string(RotateGraphemesBytes([]byte(t), shift))
+}
+
+// RotateGraphemesBytes rotates the UTF-8 in b left by shift grapheme clusters,
+// in place
+func RotateGraphemesBytes(b []byte, shift int) []byte {
+	return rotateUnits(b, shift, nextGrapheme)
+}
+
+// rotateUnits rotates b by shift units, where next says how many bytes the unit
+// at the front of its argument takes up
+func rotateUnits(b []byte, shift int, next func([]byte) int) []byte {
+	count := 0
+	for i := 0; i < len(b); i += next(b[i:]) {
+		count++
+	}
+	if count == 0 {
+		return b
+	}
+	shift = normalize(shift, count)
+	if shift == 0 {
+		return b
+	}
+	offset := 0
+	for i := 0; i < shift; i++ {
+		offset += next(b[offset:])
+	}
+	return RotateReverse(b, offset)
+}
+
+const (
+	zeroWidthJoiner = '\u200d'
+	regionalA       = '\U0001F1E6'
+	regionalZ       = '\U0001F1FF'
+)
+
+// nextGrapheme returns the length in bytes of the grapheme cluster at the start
+// of b. It covers the cases that come up in real text, not all of UAX #29:
+// CR LF, a base followed by combining marks, variation selectors and skin tone
+// modifiers, pairs of regional indicators (flags), and emoji joined by ZWJ.
+// Hangul jamo sequences and Indic conjuncts split where the full rules wouldn't,
+// precomposed Hangul is a single rune anyway.
+func nextGrapheme(b []byte) int {
+	r, size := utf8.DecodeRune(b)
+	if r == '\r' && size < len(b) && b[size] == '\n' {
+		return size + 1
+	}
+	if unicode.IsControl(r) {
+		return size
+	}
+	if isRegional(r) {
+		if next, n := utf8.DecodeRune(b[size:]); isRegional(next) {
+			size += n
+		}
+	}
+	for size < len(b) {
+		next, n := utf8.DecodeRune(b[size:])
+		switch {
+		case isExtend(next):
+			size += n
+		case next == zeroWidthJoiner:
+			size += n
+			// the joiner glues on the following emoji too
+			if following, m := utf8.DecodeRune(b[size:]); unicode.Is(unicode.So, following) {
+				size += m
+			}
+		default:
+			return size
+		}
+	}
+	return size
+}
+
+func isRegional(r rune) bool {
+	return r >= regionalA && r <= regionalZ
+}
+
+// isExtend is true for runes that never start a character: combining marks
+// (which include the variation selectors) and the emoji skin tone modifiers
+func isExtend(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc) || (r >= '\U0001F3FB' && r <= '\U0001F3FF')
+}
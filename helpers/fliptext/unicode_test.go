@@ -0,0 +1,88 @@
+package fliptext
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestRotateRunes(t *testing.T) {
+	tests := []struct {
+		text     string
+		shift    int
+		expected string
+	}{
+		{"héllo", 1, "élloh"},
+		{"héllo", 2, "llohé"},
+		{"héllo", -1, "ohéll"},
+		{"héllo", 5, "héllo"},
+		{"abcпривет日本語", 3, "привет日本語abc"},
+		{"abcпривет日本語", 9, "日本語abcпривет"},
+		{"日本語", -4, "語日本"},
+		{"", 3, ""},
+	}
+	for _, test := range tests {
+		if res := RotateRunes(test.text, test.shift); res != test.expected {
+			t.Errorf("%q by %v: got %q, expected %q", test.text, test.shift, res, test.expected)
+		}
+	}
+}
+
+func TestRotateGraphemes(t *testing.T) {
+	const (
+		decomposed = "e\u0301"                                    // e + combining acute
+		family     = "\U0001F468\u200d\U0001F469\u200d\U0001F467" // man ZWJ woman ZWJ girl
+		flag       = "\U0001F1EF\U0001F1F5"                       // JP
+		thumbs     = "\U0001F44D\U0001F3FD"                       // thumbs up, medium skin
+		heart      = "\u2764\ufe0f"                               // heart + emoji presentation
+		hindi      = "\u0915\u093f"                               // ki, consonant + vowel sign
+	)
+	tests := []struct {
+		text     string
+		shift    int
+		expected string
+	}{
+		{"h" + decomposed + "llo", 2, "llo" + "h" + decomposed},
+		{"a" + family + "b", 1, family + "ba"},
+		{"a" + family + "b", 2, "b" + "a" + family},
+		{flag + flag + "x", 1, flag + "x" + flag},
+		{thumbs + heart + "ok", -2, "ok" + thumbs + heart},
+		{hindi + "ab", 1, "ab" + hindi},
+		{"one\r\ntwo", 4, "two" + "one\r\n"},
+		{"", 1, ""},
+	}
+	for _, test := range tests {
+		res := RotateGraphemes(test.text, test.shift)
+		if res != test.expected {
+			t.Errorf("%q by %v: got %q, expected %q", test.text, test.shift, res, test.expected)
+		}
+		if !utf8.ValidString(res) {
+			t.Errorf("%q by %v isn't valid UTF-8 any more", test.text, test.shift)
+		}
+	}
+}
+
+func TestRotateRunesBytesInPlace(t *testing.T) {
+	b := []byte("añb")
+	res := RotateRunesBytes(b, 2)
+	if string(b) != "bañ" || &res[0] != &b[0] {
+		t.Errorf("expected bañ rotated in place, got %q", b)
+	}
+}
+
+func TestRotateBytesSplitsRunes(t *testing.T) {
+	// the reason any of this exists
+	if utf8.ValidString(RotateTextNaiveLessMem("héllo", 2)) {
+		t.Error("byte rotation of héllo by 2 should split the é")
+	}
+	if !utf8.ValidString(RotateRunes("héllo", 2)) {
+		t.Error("rune rotation split a rune")
+	}
+}
+
+// invalid bytes count as a rune each, and still come back out intact
+func TestRotateRunesInvalid(t *testing.T) {
+	text := "a\xffb\xfe"
+	if res := RotateRunes(text, 1); res != "\xffb\xfea" {
+		t.Errorf("got %q", res)
+	}
+}
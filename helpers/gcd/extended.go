@@ -0,0 +1,204 @@
+package gcd
+
+import (
+	"errors"
+	"math/big"
+	"math/bits"
+)
+
+// Signed is every signed integer type
+type Signed interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64
+}
+
+// Unsigned is every unsigned integer type
+type Unsigned interface {
+	~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// Integer is every integer type. The generic functions do their work on uint64
+// magnitudes, so any of these fit.
+type Integer interface {
+	Signed | Unsigned
+}
+
+var (
+	// ErrOverflow means the answer doesn't fit in the input type
+	ErrOverflow = errors.New("gcd: result overflows")
+	// ErrNoInverse means a and m share a factor, so a has no inverse mod m
+	ErrNoInverse = errors.New("gcd: no modular inverse")
+)
+
+// abs is the magnitude of a as a uint64. It's right even for the most negative
+// value of a signed type, which has no positive counterpart in its own type.
+func abs[T Integer](a T) uint64 {
+	if a < 0 {
+		return -uint64(int64(a))
+	}
+	return uint64(a)
+}
+
+// fits converts v back to T, or reports false if it doesn't fit
+func fits[T Integer](v uint64) (T, bool) {
+	t := T(v)
+	return t, t >= 0 && uint64(t) == v
+}
+
+// gcd64 is what the generic functions use. BenchmarkBySize has the binary
+// algorithm ahead at every operand size from 8 bits to 64, Euclid's divisions cost
+// more than the shifts and Lehmer's bookkeeping doesn't pay off in a single word.
+// It's BinaryGCD written out on uint64 rather than a call to it, since uint is only
+// 32 bits on some platforms and the operands here are always 64.
+func gcd64(a, b uint64) uint64 {
+	if a == 0 {
+		return b
+	}
+	if b == 0 {
+		return a
+	}
+	shift := bits.TrailingZeros64(a | b)
+	a >>= bits.TrailingZeros64(a)
+	for b != 0 {
+		b >>= bits.TrailingZeros64(b)
+		if a > b {
+			a, b = b, a
+		}
+		b -= a
+	}
+	return a << shift
+}
+
+// GCD returns the greatest common divisor of a and b for any integer type. Signs
+// are ignored, so the answer is never negative, and GCD(0, 0) is 0. The one
+// answer that can't be represented is GCD(math.MinInt64, 0) and friends, 2^63
+// doesn't fit in an int64; it comes back as math.MinInt64.
+func GCD[T Integer](a, b T) T {
+	return T(gcd64(abs(a), abs(b)))
+}
+
+// GCDOf is the GCD of all of values, 0 for none. It stops early once the GCD
+// reaches 1, since nothing can make it smaller.
+func GCDOf[T Integer](values ...T) T {
+	var g uint64
+	for _, v := range values {
+		g = gcd64(g, abs(v))
+		if g == 1 {
+			break
+		}
+	}
+	return T(g)
+}
+
+// ExtendedGCD returns g = GCD(a, b) along with Bézout coefficients x and y such
+// that a*x + b*y = g. Of all the pairs that work, this is the one Euclid's
+// algorithm finds, where |x| <= |b/g| and |y| <= |a/g|, so they can't overflow.
+// The most negative value of T is the exception, same as for GCD.
+func ExtendedGCD[T Signed](a, b T) (g, x, y T) {
+	oldR, r := a, b
+	oldX, x := T(1), T(0)
+	oldY, y := T(0), T(1)
+	for r != 0 {
+		q := oldR / r
+		oldR, r = r, oldR-q*r
+		oldX, x = x, oldX-q*x
+		oldY, y = y, oldY-q*y
+	}
+	if oldR < 0 {
+		return -oldR, -oldX, -oldY
+	}
+	return oldR, oldX, oldY
+}
+
+// LCM returns the least common multiple of a and b, or ErrOverflow if it doesn't
+// fit in T. LCM with 0 is 0, and like GCD it's never negative.
+func LCM[T Integer](a, b T) (T, error) {
+	ua, ub := abs(a), abs(b)
+	if ua == 0 || ub == 0 {
+		return 0, nil
+	}
+	hi, lo := bits.Mul64(ua/gcd64(ua, ub), ub)
+	l, ok := fits[T](lo)
+	if hi != 0 || !ok {
+		return 0, ErrOverflow
+	}
+	return l, nil
+}
+
+// ModInverse returns x in 0..m-1 with a*x = 1 mod m. a can be negative, m has to
+// be positive. If a and m share a factor there's no such x and it returns
+// ErrNoInverse.
+//
+// It's extended Euclid keeping only the coefficient of a, reduced mod m at every
+// step so it stays unsigned and never overflows even for a uint64 m.
+func ModInverse[T Integer](a, m T) (T, error) {
+	if m <= 0 {
+		return 0, errors.New("gcd: modulus must be positive")
+	}
+	um := uint64(m)
+	ua := abs(a) % um
+	if a < 0 && ua != 0 {
+		ua = um - ua
+	}
+
+	r0, r1 := um, ua
+	t0, t1 := uint64(0), uint64(1)
+	for r1 != 0 {
+		q := r0 / r1
+		r0, r1 = r1, r0-q*r1
+		// t0 - q*t1 mod m, without going negative
+		hi, lo := bits.Mul64(q%um, t1)
+		t0, t1 = t1, subMod(t0, bits.Rem64(hi, lo, um), um)
+	}
+	if r0 != 1 {
+		return 0, ErrNoInverse
+	}
+	return T(t0 % um), nil
+}
+
+// subMod is a - b mod m for a, b < m
+func subMod(a, b, m uint64) uint64 {
+	if a >= b {
+		return a - b
+	}
+	return m - (b - a)
+}
+
+// BigGCD is GCD for *big.Int, which big.Int.GCD only does for positive inputs
+func BigGCD(a, b *big.Int) *big.Int {
+	return new(big.Int).GCD(nil, nil, new(big.Int).Abs(a), new(big.Int).Abs(b))
+}
+
+// BigExtendedGCD is ExtendedGCD for *big.Int
+func BigExtendedGCD(a, b *big.Int) (g, x, y *big.Int) {
+	g, x, y = new(big.Int), new(big.Int), new(big.Int)
+	g.GCD(x, y, new(big.Int).Abs(a), new(big.Int).Abs(b))
+	// GCD worked on |a| and |b|, flip the coefficients back for negative inputs
+	if a.Sign() < 0 {
+		x.Neg(x)
+	}
+	if b.Sign() < 0 {
+		y.Neg(y)
+	}
+	return g, x, y
+}
+
+// BigLCM is LCM for *big.Int. It can't overflow, so there's no error.
+func BigLCM(a, b *big.Int) *big.Int {
+	if a.Sign() == 0 || b.Sign() == 0 {
+		return new(big.Int)
+	}
+	l := new(big.Int).Quo(a, BigGCD(a, b))
+	return l.Abs(l.Mul(l, b))
+}
+
+// BigModInverse is ModInverse for *big.Int
+func BigModInverse(a, m *big.Int) (*big.Int, error) {
+	if m.Sign() <= 0 {
+		return nil, errors.New("gcd: modulus must be positive")
+	}
+	inv := new(big.Int).ModInverse(new(big.Int).Mod(a, m), m)
+	if inv == nil {
+		return nil, ErrNoInverse
+	}
+	return inv, nil
+}
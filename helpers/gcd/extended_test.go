@@ -0,0 +1,192 @@
+package gcd
+
+import (
+	"math"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+const (
+	propertySeed   = 47
+	propertyTrials = 5000
+)
+
+// randomInt64 gives a mix of small values, which share factors often, and
+// full-range ones, which hit the overflow edges
+func randomInt64(r *rand.Rand) int64 {
+	switch r.Intn(4) {
+	case 0:
+		return r.Int63n(100) - 50
+	case 1:
+		return r.Int63n(1<<20) * int64(r.Intn(12)+1)
+	case 2:
+		return int64(r.Uint64())
+	default:
+		return []int64{0, 1, -1, math.MaxInt64, math.MinInt64}[r.Intn(5)]
+	}
+}
+
+func TestGCDProperties(t *testing.T) {
+	r := rand.New(rand.NewSource(propertySeed))
+	for i := 0; i < propertyTrials; i++ {
+		a, b := randomInt64(r), randomInt64(r)
+		g := abs(GCD(a, b))
+		expected := BigGCD(big.NewInt(a), big.NewInt(b))
+		if new(big.Int).SetUint64(g).Cmp(expected) != 0 {
+			t.Fatalf("GCD(%v, %v) = %v, expected %v", a, b, g, expected)
+		}
+		// g divides both
+		if g != 0 && (abs(a)%g != 0 || abs(b)%g != 0) {
+			t.Fatalf("GCD(%v, %v) = %v doesn't divide both", a, b, g)
+		}
+	}
+}
+
+func TestGCDTypes(t *testing.T) {
+	if g := GCD[int8](-128, 96); g != 32 {
+		t.Errorf("int8: got %v", g)
+	}
+	if g := GCD[uint8](255, 85); g != 85 {
+		t.Errorf("uint8: got %v", g)
+	}
+	if g := GCD[uint64](math.MaxUint64, 3*5*17*257); g != 3*5*17*257 {
+		t.Errorf("uint64: got %v", g)
+	}
+	if g := GCD(-12, -18); g != 6 {
+		t.Errorf("int: got %v", g)
+	}
+	if g := GCD(0, 0); g != 0 {
+		t.Errorf("GCD(0, 0) is %v", g)
+	}
+}
+
+func TestGCDOf(t *testing.T) {
+	tests := []struct {
+		values   []int
+		expected int
+	}{
+		{nil, 0},
+		{[]int{7}, 7},
+		{[]int{-7}, 7},
+		{[]int{12, 18, 30}, 6},
+		{[]int{0, 0, 9}, 9},
+		{[]int{4, 9, 1000}, 1},
+	}
+	for _, test := range tests {
+		if g := GCDOf(test.values...); g != test.expected {
+			t.Errorf("GCDOf(%v) = %v, expected %v", test.values, g, test.expected)
+		}
+	}
+}
+
+func TestExtendedGCDProperties(t *testing.T) {
+	r := rand.New(rand.NewSource(propertySeed))
+	for i := 0; i < propertyTrials; i++ {
+		a, b := randomInt64(r), randomInt64(r)
+		if a == math.MinInt64 || b == math.MinInt64 {
+			continue
+		}
+		g, x, y := ExtendedGCD(a, b)
+		if uint64(g) != abs(GCD(a, b)) {
+			t.Fatalf("ExtendedGCD(%v, %v) gave g = %v", a, b, g)
+		}
+		// Bézout: a*x + b*y = g, checked in big so the products can't overflow
+		sum := new(big.Int).Mul(big.NewInt(a), big.NewInt(x))
+		sum.Add(sum, new(big.Int).Mul(big.NewInt(b), big.NewInt(y)))
+		if sum.Cmp(big.NewInt(g)) != 0 {
+			t.Fatalf("%v*%v + %v*%v = %v, not %v", a, x, b, y, sum, g)
+		}
+
+		bg, bx, by := BigExtendedGCD(big.NewInt(a), big.NewInt(b))
+		sum.Mul(big.NewInt(a), bx)
+		sum.Add(sum, new(big.Int).Mul(big.NewInt(b), by))
+		if bg.Cmp(big.NewInt(g)) != 0 || sum.Cmp(bg) != 0 {
+			t.Fatalf("BigExtendedGCD(%v, %v) gave %v %v %v", a, b, bg, bx, by)
+		}
+	}
+}
+
+func TestLCM(t *testing.T) {
+	r := rand.New(rand.NewSource(propertySeed))
+	for i := 0; i < propertyTrials; i++ {
+		a, b := randomInt64(r), randomInt64(r)
+		l, err := LCM(a, b)
+		expected := BigLCM(big.NewInt(a), big.NewInt(b))
+		if !expected.IsInt64() {
+			if err != ErrOverflow {
+				t.Fatalf("LCM(%v, %v) = %v should overflow", a, b, l)
+			}
+			continue
+		}
+		if err != nil || l != expected.Int64() {
+			t.Fatalf("LCM(%v, %v) = %v %v, expected %v", a, b, l, err, expected)
+		}
+		// gcd * lcm = |a*b|
+		if a != 0 && b != 0 {
+			product := new(big.Int).Mul(big.NewInt(a), big.NewInt(b))
+			check := new(big.Int).Mul(new(big.Int).SetUint64(abs(GCD(a, b))), big.NewInt(l))
+			if check.Cmp(product.Abs(product)) != 0 {
+				t.Fatalf("gcd * lcm of %v and %v isn't |a*b|", a, b)
+			}
+		}
+	}
+
+	if l, err := LCM[uint8](16, 15); err != nil || l != 240 {
+		t.Errorf("LCM[uint8](16, 15) = %v %v", l, err)
+	}
+	if _, err := LCM[uint8](16, 17); err != ErrOverflow {
+		t.Error("LCM[uint8](16, 17) should overflow")
+	}
+	if _, err := LCM[int8](-8, 16); err != nil {
+		t.Error(err)
+	}
+	if _, err := LCM[int8](127, 2); err != ErrOverflow {
+		t.Error("LCM[int8](127, 2) should overflow")
+	}
+}
+
+func TestModInverse(t *testing.T) {
+	r := rand.New(rand.NewSource(propertySeed))
+	for i := 0; i < propertyTrials; i++ {
+		a, m := randomInt64(r), randomInt64(r)
+		if m <= 0 {
+			continue
+		}
+		inv, err := ModInverse(a, m)
+		expected, bigErr := BigModInverse(big.NewInt(a), big.NewInt(m))
+		if m == 1 {
+			// everything is 0 mod 1, and 0 counts as its own inverse there
+			if err != nil || inv != 0 {
+				t.Fatalf("ModInverse(%v, 1) = %v %v", a, inv, err)
+			}
+			continue
+		}
+		if bigErr != nil {
+			if err != ErrNoInverse {
+				t.Fatalf("ModInverse(%v, %v) = %v, expected no inverse", a, m, inv)
+			}
+			continue
+		}
+		if err != nil || inv != expected.Int64() {
+			t.Fatalf("ModInverse(%v, %v) = %v %v, expected %v", a, m, inv, err, expected)
+		}
+	}
+
+	// a modulus past MaxInt64 needs the unsigned arithmetic to hold up
+	const m = math.MaxUint64 // 3 * 5 * 17 * 257 * 641 * 65537 * 6700417
+	inv, err := ModInverse[uint64](2, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check := new(big.Int).Mul(big.NewInt(2), new(big.Int).SetUint64(inv))
+	if check.Mod(check, new(big.Int).SetUint64(m)).Cmp(big.NewInt(1)) != 0 {
+		t.Errorf("2 * %v isn't 1 mod 2^64-1", inv)
+	}
+	if _, err := ModInverse[uint64](3, m); err != ErrNoInverse {
+		t.Error("3 shares a factor with 2^64-1")
+	}
+	if _, err := ModInverse(3, -7); err == nil {
+		t.Error("accepted a negative modulus")
+	}
+}
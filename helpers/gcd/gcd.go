@@ -1,44 +1,45 @@
 package gcd
 
-// EuclidGCD performs the basic recursive GCD algorithm. In Go 1.3 this outperforms the other two
+import (
+	"math/bits"
+)
+
+// EuclidGCD performs the basic GCD algorithm. It used to be recursive, which is
+// the way it's usually written down, but a loop can't run out of stack and Go
+// doesn't eliminate tail calls.
 func EuclidGCD(a, b uint) uint {
-	if b == 0 {
-		return a
+	for b != 0 {
+		a, b = b, a%b
 	}
-	return EuclidGCD(b, a%b)
+	return a
 }
 
-// BinaryGCD is the recursive form of the Binary GCD algorithm
-// It's the slowest, I think in part because Go's bit manipulation functions are slow
-// from wikipedia, http://en.wikipedia.org/wiki/Binary_GCD_algorithm
+// BinaryGCD is the Binary GCD algorithm (Stein's), from wikipedia,
+// http://en.wikipedia.org/wiki/Binary_GCD_algorithm
+//
+// The recursive version stripped factors of 2 one call at a time, and was the
+// slowest of the lot. bits.TrailingZeros is a single instruction on anything
+// modern, so every run of twos now goes in one shift and it's a tight loop of
+// subtract and shift.
 func BinaryGCD(a, b uint) uint {
-	// simple cases (termination)
-	if a == b {
-		return b
-	}
 	if a == 0 {
 		return b
 	}
 	if b == 0 {
 		return a
 	}
-	// look for factors of 2
-	if (^a & 1) != 0 {
-		if (b & 1) != 0 { // b is odd
-			return BinaryGCD(a>>1, b)
-		} else {
-			return (BinaryGCD(a>>1, b>>1) << 1)
+	// the common factors of 2 come back at the end
+	shift := bits.TrailingZeros(a | b)
+	a >>= bits.TrailingZeros(a)
+	for b != 0 {
+		// a is odd from here on, so any twos in b aren't common
+		b >>= bits.TrailingZeros(b)
+		if a > b {
+			a, b = b, a
 		}
+		b -= a
 	}
-
-	if (^b & 1) != 0 {
-		return BinaryGCD(a, b>>1)
-	}
-	// reduce larger argument
-	if a > b {
-		return BinaryGCD((a-b)>>1, b)
-	}
-	return BinaryGCD((b-a)>>1, a)
+	return a << shift
 }
 
 // IterativeBinaryGCD tries to be smarter about things
@@ -88,3 +89,52 @@ func IterativeBinaryGCD(a, b uint) uint {
 	/* restore common factors of 2 */
 	return a << shift
 }
+
+// LehmerGCD is Lehmer's algorithm (Knuth 4.5.2, Algorithm L). Most Euclid steps
+// only depend on the leading digits, so it runs Euclid on the top 32 bits, which
+// is cheap, collects what those steps did as a 2x2 matrix of cofactors, and applies
+// the matrix to the full values in one go. When the top bits can't decide the next
+// quotient it falls back to one real division step.
+//
+// It's the algorithm that pays off for multi-word numbers, math/big uses it. On a
+// single word BinaryGCD still beats it at every size, see BenchmarkBySize, and
+// below 2^32 it's just Euclid.
+func LehmerGCD(a, b uint) uint {
+	return uint(lehmer(uint64(a), uint64(b)))
+}
+
+func lehmer(a, b uint64) uint64 {
+	if a < b {
+		a, b = b, a
+	}
+	for b>>32 != 0 {
+		// the top 32 bits of a, and the matching bits of b
+		shift := uint(bits.Len64(a) - 32)
+		x, y := int64(a>>shift), int64(b>>shift)
+
+		// cofactors: the real a and b are A*a + B*b and C*a + D*b
+		A, B, C, D := int64(1), int64(0), int64(0), int64(1)
+		for y+C != 0 && y+D != 0 {
+			// the quotient is only certain if both ends of its range agree
+			q := (x + A) / (y + C)
+			if q != (x+B)/(y+D) {
+				break
+			}
+			A, C = C, A-q*C
+			B, D = D, B-q*D
+			x, y = y, x-q*y
+		}
+
+		if B == 0 {
+			// the top bits didn't get anywhere, take a full step
+			a, b = b, a%b
+			continue
+		}
+		// the true results are in 0..a, so wrapping uint64 arithmetic gets them exactly
+		a, b = uint64(A)*a+uint64(B)*b, uint64(C)*a+uint64(D)*b
+	}
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
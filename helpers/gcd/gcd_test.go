@@ -1,6 +1,9 @@
 package gcd
 
 import (
+	"fmt"
+	"math/big"
+	"math/rand"
 	"testing"
 )
 
@@ -65,3 +68,123 @@ func BenchmarkIterativeBinaryGCD(b *testing.B) {
 		}
 	}
 }
+
+func TestLehmerGCD(t *testing.T) {
+	for i, set := range tests {
+		if res := LehmerGCD(set[0], set[1]); res != set[2] {
+			t.Errorf("%v: res is %v, expected %v\n", i, res, set[2])
+		}
+	}
+}
+func BenchmarkLehmerGCD(b *testing.B) {
+	for j := 0; j < b.N; j++ {
+		for i, set := range tests {
+			if res := LehmerGCD(set[0], set[1]); res != set[2] {
+				b.Errorf("%v: res is %v, expected %v\n", i, res, set[2])
+			}
+		}
+	}
+}
+
+// implementations is every uint GCD in the package, for the tests and benchmarks
+// that run all of them the same way
+var implementations = []struct {
+	name string
+	gcd  func(a, b uint) uint
+}{
+	{"Euclid", EuclidGCD},
+	{"Binary", BinaryGCD},
+	{"IterativeBinary", IterativeBinaryGCD},
+	{"Lehmer", LehmerGCD},
+}
+
+// operands makes n pairs with the given number of bits. Half of them get a
+// common factor so the answers aren't all 1.
+func operands(r *rand.Rand, n int, size uint) [][2]uint {
+	pairs := make([][2]uint, n)
+	for i := range pairs {
+		a, b := uint(r.Uint64()>>(64-size)), uint(r.Uint64()>>(64-size))
+		if i%2 == 0 && size > 8 {
+			f := uint(r.Intn(1<<8) + 1)
+			a, b = a/f*f, b/f*f
+		}
+		pairs[i] = [2]uint{a, b}
+	}
+	return pairs
+}
+
+func TestImplementationsAgree(t *testing.T) {
+	r := rand.New(rand.NewSource(48))
+	for _, size := range []uint{8, 16, 32, 48, 63, 64} {
+		for _, pair := range operands(r, 2000, size) {
+			a, b := pair[0], pair[1]
+			expected := new(big.Int).GCD(nil, nil, new(big.Int).SetUint64(uint64(a)), new(big.Int).SetUint64(uint64(b)))
+			// big only takes positive inputs
+			if a == 0 || b == 0 {
+				expected.SetUint64(uint64(a | b))
+			}
+			for _, impl := range implementations {
+				if res := impl.gcd(a, b); uint64(res) != expected.Uint64() {
+					t.Fatalf("%v(%v, %v) = %v, expected %v", impl.name, a, b, res, expected)
+				}
+			}
+		}
+	}
+}
+
+// BenchmarkBySize runs every implementation on operands of increasing size, it's
+// what gcd64's choice is based on
+func BenchmarkBySize(b *testing.B) {
+	for _, size := range []uint{8, 16, 32, 48, 64} {
+		pairs := operands(rand.New(rand.NewSource(48)), 1024, size)
+		for _, impl := range implementations {
+			b.Run(fmt.Sprintf("bits=%v/%v", size, impl.name), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					pair := pairs[i%len(pairs)]
+					impl.gcd(pair[0], pair[1])
+				}
+			})
+		}
+	}
+}
+
+// bigGCD is the reference answer. big.Int.GCD wants positive inputs, GCD(a, 0) is a.
+func bigGCD(a, b uint64) uint64 {
+	if a == 0 || b == 0 {
+		return a | b
+	}
+	return new(big.Int).GCD(nil, nil, new(big.Int).SetUint64(a), new(big.Int).SetUint64(b)).Uint64()
+}
+
+// FuzzGCD checks every implementation against math/big. Run it for a while with
+//
+//	go test -fuzz FuzzGCD ./helpers/gcd
+func FuzzGCD(f *testing.F) {
+	for _, set := range tests {
+		f.Add(uint64(set[0]), uint64(set[1]))
+	}
+	f.Add(uint64(0), uint64(0))
+	f.Add(^uint64(0), ^uint64(0)-1)
+	f.Add(uint64(1)<<63, uint64(1)<<62)
+	f.Add(uint64(1)<<63, uint64(0))
+	f.Add(uint64(12200160415121876738), uint64(7540113804746346429)) // neighboring Fibonacci numbers, Euclid's worst case
+
+	f.Fuzz(func(t *testing.T, a, b uint64) {
+		// uint is only 32 bits on some platforms
+		ua, ub := uint(a), uint(b)
+		expected := bigGCD(uint64(ua), uint64(ub))
+		for _, impl := range implementations {
+			if res := impl.gcd(ua, ub); uint64(res) != expected {
+				t.Errorf("%v(%v, %v) = %v, expected %v", impl.name, ua, ub, res, expected)
+			}
+		}
+		if res := GCD(a, b); res != bigGCD(a, b) {
+			t.Errorf("GCD[uint64](%v, %v) = %v, expected %v", a, b, res, bigGCD(a, b))
+		}
+		// the same bits as signed numbers, where the magnitudes are what count
+		sa, sb := int64(a), int64(b)
+		if res := GCD(sa, sb); uint64(res) != bigGCD(abs(sa), abs(sb)) {
+			t.Errorf("GCD[int64](%v, %v) = %v, expected %v", sa, sb, res, bigGCD(abs(sa), abs(sb)))
+		}
+	})
+}
@@ -0,0 +1,279 @@
+package gcd
+
+import (
+	"github.com/Stantheman/pearls/helpers/bitmap"
+	"math"
+	"math/bits"
+	"sort"
+)
+
+// The Column 1 primality exercises and picking hash table sizes both keep needing
+// primes, so here they are: a sieve for lots of them, Miller-Rabin for one at a
+// time, and Pollard's rho to take composites apart.
+
+// DefaultSegmentSize is how many numbers Sieve marks per segment. 1<<18 bits is
+// 32KB, which stays in L1/L2 cache while every base prime crosses it.
+const DefaultSegmentSize = 1 << 18
+
+// Sieve calls emit with each prime in lo..hi-1 in increasing order, stopping early
+// if emit returns false. It's the Sieve of Eratosthenes done a segment at a time,
+// so it needs segmentSize bits plus the primes up to sqrt(hi), not a bit for every
+// number up to hi. A segmentSize of 0 uses DefaultSegmentSize.
+func Sieve(lo, hi, segmentSize uint64, emit func(p uint64) bool) {
+	if lo < 2 {
+		lo = 2
+	}
+	if hi <= lo {
+		return
+	}
+	if segmentSize == 0 {
+		segmentSize = DefaultSegmentSize
+	}
+	base := smallPrimes(isqrt(hi - 1))
+	composite := bitmap.NewBitset(segmentSize)
+
+	for start := lo; start < hi; {
+		end := hi
+		if hi-start > segmentSize {
+			end = start + segmentSize
+		}
+		composite.Reset()
+		for _, p := range base {
+			if p*p >= end {
+				break
+			}
+			// the first multiple of p in the segment, skipping p itself. rounding
+			// start up has to be careful, start+p can overflow near 2^64
+			m := start
+			if rem := start % p; rem != 0 {
+				if p-rem >= end-start {
+					continue
+				}
+				m += p - rem
+			}
+			for m = max(m, p*p); m < end; m += p {
+				composite.Set(m - start)
+				if end-m <= p {
+					break
+				}
+			}
+		}
+		for i := uint64(0); i < end-start; i++ {
+			if !composite.Test(i) && !emit(start+i) {
+				return
+			}
+		}
+		start = end
+	}
+}
+
+// Primes returns every prime in lo..hi-1
+func Primes(lo, hi uint64) []uint64 {
+	var primes []uint64
+	Sieve(lo, hi, 0, func(p uint64) bool {
+		primes = append(primes, p)
+		return true
+	})
+	return primes
+}
+
+// smallPrimes is the plain sieve for the primes up to n, which Sieve uses as its
+// base. n is at most 2^32, and only odd numbers get a bit.
+func smallPrimes(n uint64) []uint64 {
+	if n < 2 {
+		return nil
+	}
+	primes := []uint64{2}
+	// bit i is the odd number 2i+1
+	composite := bitmap.NewBitset(n/2 + 1)
+	for i := uint64(1); 2*i+1 <= n; i++ {
+		if composite.Test(i) {
+			continue
+		}
+		p := 2*i + 1
+		primes = append(primes, p)
+		for m := p * p; m <= n; m += 2 * p {
+			composite.Set(m / 2)
+		}
+	}
+	return primes
+}
+
+// isqrt is the largest r with r*r <= n
+func isqrt(n uint64) uint64 {
+	r := uint64(math.Sqrt(float64(n)))
+	// the float can be off by one either way up near 2^64
+	for r > 0 && (r > math.MaxUint32 || r*r > n) {
+		r--
+	}
+	for r < math.MaxUint32 && (r+1)*(r+1) <= n {
+		r++
+	}
+	return r
+}
+
+// witnesses are enough Miller-Rabin bases to be exact for every n < 3.3 * 10^24,
+// which covers all of uint64 (Sorenson and Webster)
+var witnesses = []uint64{2, 3, 5, 7, 11, 13, 17, 19, 23, 29, 31, 37}
+
+// IsPrime reports whether n is prime. It's Miller-Rabin, but with a fixed set of
+// bases that's been checked to have no liars below 2^64, so it's never wrong.
+func IsPrime(n uint64) bool {
+	if n < 2 {
+		return false
+	}
+	for _, p := range witnesses {
+		if n%p == 0 {
+			return n == p
+		}
+	}
+	// n-1 = d * 2^s with d odd
+	s := bits.TrailingZeros64(n - 1)
+	d := (n - 1) >> s
+	for _, a := range witnesses {
+		x := powMod(a, d, n)
+		if x == 1 || x == n-1 {
+			continue
+		}
+		composite := true
+		for r := 1; r < s; r++ {
+			x = mulMod(x, x, n)
+			if x == n-1 {
+				composite = false
+				break
+			}
+		}
+		if composite {
+			return false
+		}
+	}
+	return true
+}
+
+// NextPrime returns the smallest prime >= n, handy for sizing hash tables. The
+// largest uint64 prime is 2^64-59, past that it returns ErrOverflow.
+func NextPrime(n uint64) (uint64, error) {
+	if n <= 2 {
+		return 2, nil
+	}
+	for p := n | 1; p >= n; p += 2 {
+		if IsPrime(p) {
+			return p, nil
+		}
+	}
+	return 0, ErrOverflow
+}
+
+// mulMod is a*b mod m without overflowing
+func mulMod(a, b, m uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	return bits.Rem64(hi, lo, m)
+}
+
+// powMod is a^e mod m by repeated squaring
+func powMod(a, e, m uint64) uint64 {
+	result := uint64(1)
+	a %= m
+	for ; e > 0; e >>= 1 {
+		if e&1 == 1 {
+			result = mulMod(result, a, m)
+		}
+		a = mulMod(a, a, m)
+	}
+	return result
+}
+
+// PollardRho returns a factor of n other than 1 and n. n has to be composite,
+// for a prime it gives back n.
+//
+// It walks x -> x^2 + c mod n, which cycles mod every factor p of n after about
+// sqrt(p) steps, long before it cycles mod n. When it has, GCD(|x - y|, n) pulls
+// p out. This is Brent's version, which multiplies a batch of differences together
+// mod n so there's one GCD per batch instead of one per step.
+func PollardRho(n uint64) uint64 {
+	if n%2 == 0 {
+		return 2
+	}
+	if n < 4 || IsPrime(n) {
+		return n
+	}
+	const batch = 128
+	f := func(x, c uint64) uint64 {
+		// x^2 + c mod n, where the add can carry past 2^64 for a big n
+		v := mulMod(x, x, n) + c
+		if v >= n || v < c {
+			v -= n
+		}
+		return v
+	}
+	// a fixed sequence of starting points keeps factoring reproducible
+	for c := uint64(1); ; c++ {
+		x, y, ys := uint64(2), uint64(2), uint64(2)
+		g, q := uint64(1), uint64(1)
+		for r := uint64(1); g == 1; r *= 2 {
+			x = y
+			for i := uint64(0); i < r; i++ {
+				y = f(y, c)
+			}
+			for k := uint64(0); k < r && g == 1; k += batch {
+				ys = y
+				for i := uint64(0); i < min(batch, r-k); i++ {
+					y = f(y, c)
+					q = mulMod(q, diff(x, y), n)
+				}
+				g = gcd64(q, n)
+			}
+		}
+		if g == n {
+			// the batch overshot, step back through it one at a time
+			for g = 1; g == 1; {
+				ys = f(ys, c)
+				g = gcd64(diff(x, ys), n)
+			}
+		}
+		if g != n {
+			return g
+		}
+		// x and y met mod n too, try another c
+	}
+}
+
+func diff(a, b uint64) uint64 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+// Factor returns the prime factors of n, smallest first and repeated as often as
+// they divide n. 0 and 1 have none.
+func Factor(n uint64) []uint64 {
+	var factors []uint64
+	if n == 0 {
+		return nil
+	}
+	// the small ones are quicker to divide out than to find by rho
+	for _, p := range trialPrimes {
+		for n%p == 0 {
+			factors = append(factors, p)
+			n /= p
+		}
+	}
+	factors = appendFactors(factors, n)
+	sort.Slice(factors, func(i, j int) bool { return factors[i] < factors[j] })
+	return factors
+}
+
+// trialPrimes are the primes Factor divides out before reaching for rho
+var trialPrimes = smallPrimes(1000)
+
+func appendFactors(factors []uint64, n uint64) []uint64 {
+	if n == 1 {
+		return factors
+	}
+	if IsPrime(n) {
+		return append(factors, n)
+	}
+	d := PollardRho(n)
+	return appendFactors(appendFactors(factors, d), n/d)
+}
@@ -0,0 +1,185 @@
+package gcd
+
+import (
+	"math"
+	"math/big"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// trialDivision is the obviously right primality test, for checking the others
+func trialDivision(n uint64) bool {
+	if n < 2 {
+		return false
+	}
+	for d := uint64(2); d*d <= n; d++ {
+		if n%d == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSieve(t *testing.T) {
+	var expected []uint64
+	for n := uint64(0); n < 20000; n++ {
+		if trialDivision(n) {
+			expected = append(expected, n)
+		}
+	}
+	// segments smaller than, around, and bigger than the base primes
+	for _, segment := range []uint64{1, 2, 7, 100, 1 << 10, 0} {
+		var got []uint64
+		Sieve(0, 20000, segment, func(p uint64) bool {
+			got = append(got, p)
+			return true
+		})
+		if !slices.Equal(got, expected) {
+			t.Fatalf("segment size %v: got %v primes, expected %v", segment, len(got), len(expected))
+		}
+	}
+
+	if got := Primes(90, 110); !slices.Equal(got, []uint64{97, 101, 103, 107, 109}) {
+		t.Errorf("primes in 90..109 are %v", got)
+	}
+	if got := Primes(10, 10); len(got) != 0 {
+		t.Errorf("empty range gave %v", got)
+	}
+}
+
+func TestSieveStops(t *testing.T) {
+	count := 0
+	Sieve(0, 1000, 0, func(p uint64) bool {
+		count++
+		return count < 5
+	})
+	if count != 5 {
+		t.Errorf("emit was called %v times after asking to stop at 5", count)
+	}
+}
+
+func TestSieveHighWindow(t *testing.T) {
+	// a window far from 0, where every segment needs base primes up to 10^6
+	const lo, hi = 1000000000000, 1000000000000 + 50000
+	var expected []uint64
+	for n := uint64(lo); n < hi; n++ {
+		if IsPrime(n) {
+			expected = append(expected, n)
+		}
+	}
+	if got := Primes(lo, hi); !slices.Equal(got, expected) {
+		t.Errorf("got %v primes, IsPrime found %v", len(got), len(expected))
+	}
+}
+
+func TestIsPrime(t *testing.T) {
+	for n := uint64(0); n < 10000; n++ {
+		if IsPrime(n) != trialDivision(n) {
+			t.Fatalf("IsPrime(%v) = %v", n, IsPrime(n))
+		}
+	}
+
+	tests := []struct {
+		n     uint64
+		prime bool
+	}{
+		{561, false},                 // Carmichael, fools Fermat
+		{3215031751, false},          // strong pseudoprime to bases 2, 3, 5 and 7
+		{3825123056546413051, false}, // strong pseudoprime to every base up to 23
+		{4294967291, true},           // largest prime below 2^32
+		{18446744073709551557, true}, // largest prime below 2^64
+		{math.MaxUint64, false},
+	}
+	for _, test := range tests {
+		if got := IsPrime(test.n); got != test.prime {
+			t.Errorf("IsPrime(%v) = %v", test.n, got)
+		}
+	}
+
+	r := rand.New(rand.NewSource(50))
+	for i := 0; i < 20000; i++ {
+		n := r.Uint64() | 1
+		if IsPrime(n) != new(big.Int).SetUint64(n).ProbablyPrime(20) {
+			t.Fatalf("IsPrime(%v) disagrees with math/big", n)
+		}
+	}
+}
+
+func TestNextPrime(t *testing.T) {
+	tests := [][2]uint64{{0, 2}, {2, 2}, {3, 3}, {4, 5}, {1000, 1009}, {1 << 32, 4294967311}}
+	for _, test := range tests {
+		if p, err := NextPrime(test[0]); err != nil || p != test[1] {
+			t.Errorf("NextPrime(%v) = %v %v, expected %v", test[0], p, err, test[1])
+		}
+	}
+	if _, err := NextPrime(math.MaxUint64 - 57); err != ErrOverflow {
+		t.Error("found a prime past 2^64-59")
+	}
+}
+
+func TestFactor(t *testing.T) {
+	tests := []struct {
+		n       uint64
+		factors []uint64
+	}{
+		{0, nil},
+		{1, nil},
+		{2, []uint64{2}},
+		{360, []uint64{2, 2, 2, 3, 3, 5}},
+		{1009 * 1009, []uint64{1009, 1009}},
+		{4294967291 * 4294967279, []uint64{4294967279, 4294967291}},
+		{18446744073709551557, []uint64{18446744073709551557}},
+		{math.MaxUint64, []uint64{3, 5, 17, 257, 641, 65537, 6700417}},
+	}
+	for _, test := range tests {
+		if got := Factor(test.n); !slices.Equal(got, test.factors) {
+			t.Errorf("Factor(%v) = %v, expected %v", test.n, got, test.factors)
+		}
+	}
+
+	r := rand.New(rand.NewSource(50))
+	for i := 0; i < 500; i++ {
+		n := r.Uint64()>>uint(r.Intn(40)) + 2
+		product := uint64(1)
+		for _, f := range Factor(n) {
+			if !IsPrime(f) {
+				t.Fatalf("Factor(%v) has composite %v", n, f)
+			}
+			product *= f
+		}
+		if product != n {
+			t.Fatalf("factors of %v multiply to %v", n, product)
+		}
+	}
+}
+
+func TestPollardRho(t *testing.T) {
+	for _, n := range []uint64{9, 91, 8051, 10403, 1000036000099, 4294967291 * 4294967279} {
+		d := PollardRho(n)
+		if d == 1 || d == n || n%d != 0 {
+			t.Errorf("PollardRho(%v) = %v isn't a proper factor", n, d)
+		}
+	}
+	if d := PollardRho(101); d != 101 {
+		t.Errorf("PollardRho of a prime gave %v", d)
+	}
+}
+
+func BenchmarkSieve(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		Sieve(0, 10000000, 0, func(uint64) bool { return true })
+	}
+}
+
+func BenchmarkIsPrime(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		IsPrime(18446744073709551557)
+	}
+}
+
+func BenchmarkFactor(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		Factor(4294967291 * 4294967279)
+	}
+}
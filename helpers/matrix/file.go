@@ -0,0 +1,78 @@
+package matrix
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	pbinary "github.com/Stantheman/pearls/helpers/binary"
+	"io"
+	"os"
+)
+
+// TransposeFile transposes a rows x cols matrix of big-endian uint32s, the format
+// helpers/binary writes, from input into output while holding at most avail
+// integers in memory.
+//
+// Every output row is an input column. Each pass reads the whole input in order
+// and keeps only the columns for the next avail/rows output rows, then writes
+// those rows out. That's the tag-and-sort idea without writing the tags down: an
+// element's tag is its index in the output, and each pass collects one range of
+// tags, the way LimitedSort collects one range of values per pass. It takes
+// cols/(avail/rows) passes, so avail should be at least a few times rows.
+func TransposeFile(input, output string, rows, cols, avail int) error {
+	if rows <= 0 || cols <= 0 {
+		return fmt.Errorf("can't transpose a %vx%v matrix", rows, cols)
+	}
+	if avail < rows {
+		return fmt.Errorf("need room for at least one column of %v, only have %v", rows, avail)
+	}
+
+	in, err := os.Open(input)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() != int64(rows)*int64(cols)*4 {
+		return fmt.Errorf("%v is %v bytes, a %vx%v matrix should be %v", input, info.Size(), rows, cols, rows*cols*4)
+	}
+
+	out, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	writer := bufio.NewWriter(out)
+
+	// band is how many input columns, so output rows, fit in memory per pass
+	band := min(avail/rows, cols)
+	buf := make([]uint32, band*rows)
+	for first := 0; first < cols; first += band {
+		width := min(band, cols-first)
+		if _, err := in.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		reader := pbinary.NewReader(in, 0)
+		for r := 0; r < rows; r++ {
+			for c := 0; c < cols; c++ {
+				val, err := reader.Next()
+				if err != nil {
+					return err
+				}
+				if c >= first && c < first+width {
+					buf[(c-first)*rows+r] = val
+				}
+			}
+		}
+		if err := binary.Write(writer, binary.BigEndian, buf[:width*rows]); err != nil {
+			return err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,50 @@
+package matrix
+
+import (
+	"github.com/Stantheman/pearls/helpers/binary"
+	"os"
+	"slices"
+	"testing"
+)
+
+const (
+	matrixFile     = "matrix.bin"
+	transposedFile = "transposed.bin"
+)
+
+func TestTransposeFile(t *testing.T) {
+	defer os.Remove(matrixFile)
+	defer os.Remove(transposedFile)
+
+	const rows, cols = 37, 53
+	m := make([]uint32, rows*cols)
+	for i := range m {
+		m[i] = uint32(i * 7)
+	}
+	if err := binary.MakeBinaryFile(matrixFile, m); err != nil {
+		t.Fatal(err)
+	}
+	expected := slices.Clone(m)
+	Transpose(expected, rows, cols)
+
+	// one column per pass, a few, an uneven split, and everything at once
+	for _, avail := range []int{rows, rows * 5, rows*cols/3 + 1, rows * cols * 2} {
+		if err := TransposeFile(matrixFile, transposedFile, rows, cols, avail); err != nil {
+			t.Fatal(err)
+		}
+		got, err := binary.ReadBinaryFile(transposedFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(got, expected) {
+			t.Fatalf("avail %v: file isn't transposed", avail)
+		}
+	}
+
+	if err := TransposeFile(matrixFile, transposedFile, rows, cols, rows-1); err == nil {
+		t.Error("accepted room for less than a column")
+	}
+	if err := TransposeFile(matrixFile, transposedFile, rows, cols+1, 1000); err == nil {
+		t.Error("accepted the wrong shape")
+	}
+}
@@ -0,0 +1,67 @@
+// Package matrix transposes row-major matrices, in memory and on disk
+//
+// Column 2 tells the story of a 4000x4000 matrix on tape that had to be transposed.
+// The trick was to tag each element with its row and column, sort by column, and
+// strip the tags. Here that becomes two things: cycle-following in memory, which is
+// the juggling rotation from fliptext with a different "where does this go" rule,
+// and multi-pass transposition of binary files, the same passes LimitedSort makes
+// when it can't fit the whole bitmap.
+package matrix
+
+import (
+	"fmt"
+	"github.com/Stantheman/pearls/helpers/bitmap"
+)
+
+// Transpose turns m, a rows x cols matrix stored row-major, into its cols x rows
+// transpose, in place. The matrix doesn't have to be square.
+//
+// The element at index i belongs at i*rows mod (n-1), the first and last stay put.
+// Following that from any element walks a cycle back to where it started, just like
+// fliptext.RotateDelicate, but the cycles aren't evenly sized, so a bitset marks
+// what's been moved: one bit per element on top of the matrix.
+func Transpose[T any](m []T, rows, cols int) error {
+	// multiplied as uint64, an int product can wrap around to len(m) on 32 bits
+	if rows < 0 || cols < 0 || uint64(rows)*uint64(cols) != uint64(len(m)) {
+		return fmt.Errorf("a %vx%v matrix can't have %v elements", rows, cols, len(m))
+	}
+	if rows == cols {
+		TransposeSquare(m, rows)
+		return nil
+	}
+	n := len(m)
+	if rows <= 1 || cols <= 1 {
+		// a single row or column is already laid out like its transpose
+		return nil
+	}
+
+	// the cycle arithmetic is in uint64: i*rows is bigger than n, which overflows
+	// an int on 32 bits at the book's 4000x4000
+	last, r := uint64(n-1), uint64(rows)
+	moved := bitmap.NewBitset(uint64(n))
+	for start := uint64(1); start < last; start++ {
+		if moved.Test(start) {
+			continue
+		}
+		val, i := m[start], start
+		for {
+			i = i * r % last
+			m[i], val = val, m[i]
+			moved.Set(i)
+			if i == start {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// TransposeSquare transposes an n x n matrix in place by swapping across the
+// diagonal, which needs no bookkeeping at all
+func TransposeSquare[T any](m []T, n int) {
+	for r := 0; r < n; r++ {
+		for c := r + 1; c < n; c++ {
+			m[r*n+c], m[c*n+r] = m[c*n+r], m[r*n+c]
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package matrix
+
+import (
+	"slices"
+	"testing"
+)
+
+// naive transposes into a new slice
+func naive(m []int, rows, cols int) []int {
+	t := make([]int, len(m))
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			t[c*rows+r] = m[r*cols+c]
+		}
+	}
+	return t
+}
+
+func sequence(n int) []int {
+	m := make([]int, n)
+	for i := range m {
+		m[i] = i
+	}
+	return m
+}
+
+func TestTranspose(t *testing.T) {
+	for rows := 0; rows <= 12; rows++ {
+		for cols := 0; cols <= 12; cols++ {
+			m := sequence(rows * cols)
+			expected := naive(m, rows, cols)
+			if err := Transpose(m, rows, cols); err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(m, expected) {
+				t.Fatalf("%vx%v: got %v, expected %v", rows, cols, m, expected)
+			}
+		}
+	}
+}
+
+func TestTransposeTwice(t *testing.T) {
+	m := sequence(400 * 37)
+	Transpose(m, 400, 37)
+	Transpose(m, 37, 400)
+	if !slices.Equal(m, sequence(400*37)) {
+		t.Error("transposing twice didn't give back the original")
+	}
+}
+
+// TestTransposeLarge is the book's size, give or take a column so it isn't square.
+// index*rows runs past 2^31 here, which is where int arithmetic broke on 32 bits.
+func TestTransposeLarge(t *testing.T) {
+	if testing.Short() {
+		t.Skip("16M elements")
+	}
+	const rows, cols = 4000, 4001
+	m := make([]int32, rows*cols)
+	for i := range m {
+		m[i] = int32(i)
+	}
+	if err := Transpose(m, rows, cols); err != nil {
+		t.Fatal(err)
+	}
+	for c := 0; c < cols; c++ {
+		for r := 0; r < rows; r++ {
+			if v := m[c*rows+r]; v != int32(r*cols+c) {
+				t.Fatalf("transposed [%v][%v] is %v, expected %v", c, r, v, r*cols+c)
+			}
+		}
+	}
+}
+
+func TestTransposeBadShape(t *testing.T) {
+	if err := Transpose(sequence(10), 3, 3); err == nil {
+		t.Error("accepted 10 elements as 3x3")
+	}
+}
+
+func BenchmarkTranspose(b *testing.B) {
+	shapes := []struct {
+		name       string
+		rows, cols int
+	}{
+		{"square", 1024, 1024},
+		{"wide", 256, 4096},
+		{"tall", 4096, 256},
+	}
+	for _, shape := range shapes {
+		m := sequence(shape.rows * shape.cols)
+		b.Run(shape.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				// transposing back and forth keeps the shape valid between loops
+				Transpose(m, shape.rows, shape.cols)
+				Transpose(m, shape.cols, shape.rows)
+			}
+		})
+	}
+}
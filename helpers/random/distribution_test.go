@@ -0,0 +1,173 @@
+package random
+
+import (
+	"math"
+	"sort"
+	"testing"
+)
+
+// Statistical checks on the generators. They use a fixed seed so they're
+// deterministic, and thresholds a fair generator fails about 1 time in 1000.
+
+const distributionSeed = 2014
+
+// ksUniform returns the Kolmogorov-Smirnov statistic of samples, all in [0, 1),
+// against the uniform distribution: the biggest gap between the empirical CDF
+// and the line y = x
+func ksUniform(samples []float64) float64 {
+	sorted := append([]float64(nil), samples...)
+	sort.Float64s(sorted)
+	n := float64(len(sorted))
+	d := 0.0
+	for i, x := range sorted {
+		d = math.Max(d, math.Max(float64(i+1)/n-x, x-float64(i)/n))
+	}
+	return d
+}
+
+// ksCritical999 is the 99.9% critical value of the KS statistic for n samples
+func ksCritical999(n int) float64 {
+	return 1.95 / math.Sqrt(float64(n))
+}
+
+// TestRandomIntegersChiSquare makes sure every value in range shows up, including
+// the max, which the old modulo version never produced
+func TestRandomIntegersChiSquare(t *testing.T) {
+	// 99.9% points of chi-square with 1, 7 and 15 degrees of freedom
+	// a slice and a generator per case, ranging over a map would hand each bit
+	// count different samples every run
+	critical := []struct {
+		bitcount uint32
+		limit    float64
+	}{{1, 10.83}, {3, 24.32}, {4, 37.70}}
+
+	for _, c := range critical {
+		bitcount, limit := c.bitcount, c.limit
+		g := NewSeeded(distributionSeed)
+		buckets := 1 << bitcount
+		list, err := g.GenerateRandomIntegers(buckets*2000, bitcount)
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts := make([]int, buckets)
+		for _, v := range list {
+			if int(v) >= buckets {
+				t.Fatalf("%v bits: %v is out of range", bitcount, v)
+			}
+			counts[v]++
+		}
+		if stat := chiSquare(counts, 2000); stat > limit {
+			t.Errorf("%v bits: chi-square %.2f is over %v: %v", bitcount, stat, limit, counts)
+		}
+	}
+}
+
+// TestRandomIntegersKS checks full width values are uniform over all of uint32
+func TestRandomIntegersKS(t *testing.T) {
+	g := NewSeeded(distributionSeed)
+	for _, bitcount := range []uint32{20, 32} {
+		list, err := g.GenerateRandomIntegers(10000, bitcount)
+		if err != nil {
+			t.Fatal(err)
+		}
+		samples := make([]float64, len(list))
+		for i, v := range list {
+			samples[i] = float64(v) / float64(uint64(1)<<bitcount)
+		}
+		if d := ksUniform(samples); d > ksCritical999(len(samples)) {
+			t.Errorf("%v bits: KS statistic %.4f is over %.4f", bitcount, d, ksCritical999(len(samples)))
+		}
+	}
+
+	// the top value has to be reachable at 32 bits too
+	list, _ := New(maxSource{}).GenerateRandomIntegers(1, 32)
+	if list[0] != math.MaxUint32 {
+		t.Errorf("an all ones source gave %v, expected %v", list[0], uint32(math.MaxUint32))
+	}
+}
+
+// maxSource always returns all ones
+type maxSource struct{}
+
+func (maxSource) Int63() int64    { return math.MaxInt64 }
+func (maxSource) Uint64() uint64  { return math.MaxUint64 }
+func (maxSource) Seed(seed int64) {}
+
+// TestLimitedOccurrences checks each value's copy count is uniform over 0..occur.
+// Drawing the count in the loop condition piles up on the small counts.
+func TestLimitedOccurrences(t *testing.T) {
+	const count, occur = 20000, 4
+	// 99.9% point of chi-square with 4 degrees of freedom
+	const critical = 18.47
+
+	list := NewSeeded(distributionSeed).GenerateLimitedRandomIntegers(count, occur)
+	copies := make([]int, count)
+	for _, v := range list {
+		copies[v]++
+	}
+	histogram := make([]int, occur+1)
+	for _, c := range copies {
+		histogram[c]++
+	}
+	if stat := chiSquare(histogram, float64(count)/(occur+1)); stat > critical {
+		t.Errorf("chi-square %.2f is over %v, copy counts %v", stat, critical, histogram)
+	}
+}
+
+// TestLimitedShuffled checks the whole list is shuffled. Only shuffling the first
+// count positions leaves the tail sorted, so position and value line up.
+func TestLimitedShuffled(t *testing.T) {
+	list := NewSeeded(distributionSeed).GenerateLimitedRandomIntegers(1000, 10)
+
+	// where each value lands should be uniform over the list, so the position of
+	// each element divided by the length is uniform on [0, 1)
+	n := len(list)
+	positions := make([]float64, 0, n)
+	for i, v := range list {
+		// only look at the values that the broken version never moved
+		if v >= n/10 {
+			positions = append(positions, float64(i)/float64(n))
+		}
+	}
+	if d := ksUniform(positions); d > ksCritical999(len(positions)) {
+		t.Errorf("KS statistic %.4f is over %.4f", d, ksCritical999(len(positions)))
+	}
+
+	// and the broken version left the tail in order
+	if sort.IntsAreSorted(list[n/2:]) {
+		t.Error("second half of the list is still sorted")
+	}
+}
+
+// TestIncreasingGaps checks each integer is kept with probability 1/2 by looking
+// at how often gaps of 1, 2, 3... show up
+func TestIncreasingGaps(t *testing.T) {
+	const count = 20000
+	// 99.9% point of chi-square with 5 degrees of freedom
+	const critical = 20.52
+
+	list := NewSeeded(distributionSeed).GenerateIncreasingRandomIntegers(count)
+	// buckets for gaps 1..5 and one for 6+
+	observed := make([]int, 6)
+	expected := make([]float64, 6)
+	for i := 1; i < len(list); i++ {
+		gap := list[i] - list[i-1]
+		if gap > 6 {
+			gap = 6
+		}
+		observed[gap-1]++
+	}
+	for k := range expected {
+		expected[k] = float64(count-1) / math.Pow(2, float64(k+1))
+	}
+	expected[5] *= 2
+
+	stat := 0.0
+	for k := range observed {
+		d := float64(observed[k]) - expected[k]
+		stat += d * d / expected[k]
+	}
+	if stat > critical {
+		t.Errorf("chi-square %.2f is over %v: observed %v, expected %v", stat, critical, observed, expected)
+	}
+}
@@ -1,22 +1,91 @@
 // Package random implements specific random number generator functions
+//
+// The package-level functions share a generator seeded from the clock. Tests that
+// need to reproduce their input should make their own Generator with NewSeeded and
+// log the seed.
 package random
 
 import (
 	"errors"
 	"math/rand"
+	randv2 "math/rand/v2"
 	"sort"
 	"time"
 )
 
-var r = rand.New(rand.NewSource(time.Now().UnixNano()))
+// Generator makes the random lists in this package from its own source. Two
+// Generators seeded the same way produce the same lists.
+//
+// Like rand.Rand, a Generator isn't safe for concurrent use.
+type Generator struct {
+	r    *rand.Rand
+	seed int64
+}
+
+// New makes a Generator that draws from src
+func New(src rand.Source) *Generator {
+	return &Generator{r: rand.New(src)}
+}
+
+// NewSeeded makes a Generator from a seed, which Seed hands back for logging
+func NewSeeded(seed int64) *Generator {
+	return &Generator{r: rand.New(rand.NewSource(seed)), seed: seed}
+}
+
+// NewV2 makes a Generator that draws from a math/rand/v2 source, like a PCG or ChaCha8
+func NewV2(src randv2.Source) *Generator {
+	return New(v2Source{src})
+}
+
+// Seed returns the seed the Generator was made with, or 0 if it was made from a source
+func (g *Generator) Seed() int64 {
+	return g.seed
+}
+
+// v2Source adapts a math/rand/v2 source to the math/rand interface
+type v2Source struct {
+	src randv2.Source
+}
+
+func (s v2Source) Uint64() uint64 { return s.src.Uint64() }
+func (s v2Source) Int63() int64   { return int64(s.src.Uint64() >> 1) }
+
+// Seed can't reseed a v2 source through this interface, make a new one instead
+func (s v2Source) Seed(int64) { panic("random: can't reseed a math/rand/v2 source") }
+
+var std = NewSeeded(time.Now().UnixNano())
 
 // GenerateIncreasingRandomIntegers generates a list of randomly increasing integers with length "count".
 // The list includes no duplicates.
 func GenerateIncreasingRandomIntegers(count int) (list []int) {
+	return std.GenerateIncreasingRandomIntegers(count)
+}
+
+// GenerateUniqueRandomIntegers generates a list of random unique integers.
+func GenerateUniqueRandomIntegers(count int) (list sort.IntSlice) {
+	return std.GenerateUniqueRandomIntegers(count)
+}
+
+// GenerateLimitedRandomIntegers generates a list of random integers that occur up 0..N times.
+func GenerateLimitedRandomIntegers(count, occur int) (list sort.IntSlice) {
+	return std.GenerateLimitedRandomIntegers(count, occur)
+}
+
+// GenerateRandomIntegers creates a list of 32bit integers with values up to bitcount bits
+// 20 bitcount = 1<<20-1 max, every value equally likely
+func GenerateRandomIntegers(count int, bitcount uint32) (list []uint32, err error) {
+	return std.GenerateRandomIntegers(count, bitcount)
+}
+
+// GenerateIncreasingRandomIntegers generates a list of randomly increasing integers with length "count".
+// The list includes no duplicates. Walking up from 0, each integer is kept with probability exactly 1/2,
+// so the gaps between neighbors are geometric: a gap of k shows up with probability 1/2^k.
+func (g *Generator) GenerateIncreasingRandomIntegers(count int) (list []int) {
 	list = make([]int, count)
 
 	for loop, index := 0, 0; index < len(list); loop++ {
-		if r.Float32() > 0.5 {
+		// Float32() > 0.5 was a hair under a coin flip, the low bit is exactly one
+		if g.r.Int63()&1 == 1 {
 			list[index] = loop
 			index++
 		}
@@ -25,56 +94,62 @@ func GenerateIncreasingRandomIntegers(count int) (list []int) {
 }
 
 // GenerateUniqueRandomIntegers generates a list of random unique integers.
-func GenerateUniqueRandomIntegers(count int) (list sort.IntSlice) {
+// It's a uniformly random permutation of 0..count-1.
+func (g *Generator) GenerateUniqueRandomIntegers(count int) (list sort.IntSlice) {
 	list = make([]int, count)
 
 	for i := range list {
 		list[i] = i
 	}
-
-	// starting from the end, swap with a random smaller integer until done
-	// (Fisher-Yates http://en.wikipedia.org/wiki/Fisher-Yates_shuffle)
-	for i := count - 1; i > 0; i-- {
-		// Intn is exclusive, Fisher-Yates says 0 <= j <= i
-		rand := r.Intn(i + 1)
-		list.Swap(i, rand)
-	}
+	g.shuffle(list)
 
 	return
 }
 
 // GenerateLimitedRandomIntegers generates a list of random integers that occur up 0..N times.
-func GenerateLimitedRandomIntegers(count, occur int) (list sort.IntSlice) {
+// Each of 0..count-1 independently shows up k times with k uniform over 0..occur, and the
+// whole list is uniformly shuffled.
+func (g *Generator) GenerateLimitedRandomIntegers(count, occur int) (list sort.IntSlice) {
 	// this is a horrifying way of making an always-growing list that's probably terrible
 	list = make([]int, 0)
 
 	for i := 0; i < count; i++ {
-		for j := 0; j < r.Intn(occur+1); j++ {
+		// draw the count once, drawing it in the loop condition skews toward fewer copies
+		times := g.r.Intn(occur + 1)
+		for j := 0; j < times; j++ {
 			list = append(list, i)
 		}
 	}
+	// the list is longer than count, so shuffle all of it and not just the front
+	g.shuffle(list)
+
+	return
+}
 
+// shuffle puts list in a uniformly random order
+func (g *Generator) shuffle(list sort.IntSlice) {
 	// starting from the end, swap with a random smaller integer until done
 	// (Fisher-Yates http://en.wikipedia.org/wiki/Fisher-Yates_shuffle)
-	for i := count - 1; i > 0; i-- {
+	for i := len(list) - 1; i > 0; i-- {
 		// Intn is exclusive, Fisher-Yates says 0 <= j <= i
-		rand := r.Intn(i + 1)
+		rand := g.r.Intn(i + 1)
 		list.Swap(i, rand)
 	}
-
-	return
 }
 
 // GenerateRandomIntegers creates a list of 32bit integers with values up to bitcount bits
-// 20 bitcount = 1<<20-1 max
-func GenerateRandomIntegers(count int, bitcount uint32) (list []uint32, err error) {
+// 20 bitcount = 1<<20-1 max. Every value in 0..1<<bitcount-1 is equally likely.
+func (g *Generator) GenerateRandomIntegers(count int, bitcount uint32) (list []uint32, err error) {
 	if bitcount > 32 {
 		return nil, errors.New("limit must <= 32 bits")
 	}
 	list = make([]uint32, count)
 
+	// the top bitcount bits of a uniform uint32 are uniform. the old modulo by
+	// 1<<bitcount-1 could never produce the max, and overflowed at 32 bits
+	shift := 32 - bitcount
 	for i := 0; i < count; i++ {
-		list[i] = r.Uint32() % uint32((1<<bitcount)-1)
+		list[i] = g.r.Uint32() >> shift
 	}
 	return list, nil
 }
@@ -2,6 +2,9 @@ package random
 
 import (
 	"math"
+	"math/rand"
+	randv2 "math/rand/v2"
+	"reflect"
 	"sort"
 	"testing"
 )
@@ -154,6 +157,46 @@ func TestDistributionRandom(t *testing.T) {
 	}
 }
 
+// TestGeneratorReproducible makes sure the same seed gives back the same lists,
+// which is the whole point of a Generator
+func TestGeneratorReproducible(t *testing.T) {
+	sources := map[string]func() *Generator{
+		"NewSeeded": func() *Generator { return NewSeeded(1234) },
+		"New":       func() *Generator { return New(rand.NewSource(1234)) },
+		"NewV2":     func() *Generator { return NewV2(randv2.NewPCG(1, 2)) },
+	}
+	for name, build := range sources {
+		a, b := build(), build()
+
+		if x, y := a.GenerateIncreasingRandomIntegers(ArraySize), b.GenerateIncreasingRandomIntegers(ArraySize); !reflect.DeepEqual(x, y) {
+			t.Errorf("%v: increasing lists differ: %v vs %v", name, x, y)
+		}
+		if x, y := a.GenerateUniqueRandomIntegers(ArraySize), b.GenerateUniqueRandomIntegers(ArraySize); !reflect.DeepEqual(x, y) {
+			t.Errorf("%v: unique lists differ: %v vs %v", name, x, y)
+		}
+		if x, y := a.GenerateLimitedRandomIntegers(ArraySize, 5), b.GenerateLimitedRandomIntegers(ArraySize, 5); !reflect.DeepEqual(x, y) {
+			t.Errorf("%v: limited lists differ: %v vs %v", name, x, y)
+		}
+		x, _ := a.GenerateRandomIntegers(ArraySize, 20)
+		y, _ := b.GenerateRandomIntegers(ArraySize, 20)
+		if !reflect.DeepEqual(x, y) {
+			t.Errorf("%v: random lists differ: %v vs %v", name, x, y)
+		}
+	}
+}
+
+func TestGeneratorSeed(t *testing.T) {
+	if seed := NewSeeded(99).Seed(); seed != 99 {
+		t.Errorf("seed is %v, expected 99", seed)
+	}
+	// different seeds should give different permutations
+	a := NewSeeded(1).GenerateUniqueRandomIntegers(ArraySize)
+	b := NewSeeded(2).GenerateUniqueRandomIntegers(ArraySize)
+	if reflect.DeepEqual(a, b) {
+		t.Error("seeds 1 and 2 made the same permutation")
+	}
+}
+
 func BenchmarkGenerateIncreasingRandomIntegers(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		GenerateIncreasingRandomIntegers(ArraySize)
@@ -0,0 +1,121 @@
+package random
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"iter"
+	"sort"
+)
+
+// Column 12 is about picking m distinct integers out of 0..n-1, each subset
+// equally likely. These are the algorithms from the column.
+
+func checkSample(m, n int) error {
+	if m < 0 || n < 0 || m > n {
+		return fmt.Errorf("can't pick %v distinct values out of %v", m, n)
+	}
+	return nil
+}
+
+// SampleSorted picks m distinct integers from 0..n-1 with the default generator.
+// See Generator.SampleSorted.
+func SampleSorted(m, n int) ([]int, error) {
+	return std.SampleSorted(m, n)
+}
+
+// SelectionSample picks m distinct integers from 0..n-1 with the default generator.
+// See Generator.SelectionSample.
+func SelectionSample(m, n int) ([]int, error) {
+	return std.SelectionSample(m, n)
+}
+
+// SampleLines picks m lines from r with the default generator.
+// See Generator.SampleLines.
+func SampleLines(r io.Reader, m int) ([]string, error) {
+	return std.SampleLines(r, m)
+}
+
+// SampleSorted uses Floyd's algorithm to pick m distinct integers from 0..n-1, and
+// returns them sorted. It only draws m random numbers and only stores the sample,
+// so it's the one to use when m is much smaller than n:
+//
+//	for j := n-m; j < n; j++
+//	    t := random in 0..j
+//	    if t is in S, add j, else add t
+func (g *Generator) SampleSorted(m, n int) ([]int, error) {
+	if err := checkSample(m, n); err != nil {
+		return nil, err
+	}
+	set := make(map[int]bool, m)
+	sample := make([]int, 0, m)
+	for j := n - m; j < n; j++ {
+		t := g.r.Intn(j + 1)
+		if set[t] {
+			t = j
+		}
+		set[t] = true
+		sample = append(sample, t)
+	}
+	sort.Ints(sample)
+	return sample, nil
+}
+
+// SelectionSample is Knuth's selection sampling (Algorithm S). It walks 0..n-1 in
+// order and keeps each value with probability (still needed)/(still left), so the
+// output comes out sorted without any extra work. It takes n steps.
+func (g *Generator) SelectionSample(m, n int) ([]int, error) {
+	if err := checkSample(m, n); err != nil {
+		return nil, err
+	}
+	sample := make([]int, 0, m)
+	for i := 0; i < n && len(sample) < m; i++ {
+		if g.r.Intn(n-i) < m-len(sample) {
+			sample = append(sample, i)
+		}
+	}
+	return sample, nil
+}
+
+// Reservoir picks m items from seq without knowing how long it is ahead of time,
+// using Algorithm R. The first m items fill the reservoir, then item i replaces a
+// random slot with probability m/(i+1). If seq has fewer than m items, all of them
+// are returned, and a negative m is the same as 0. A nil g uses the default generator.
+func Reservoir[T any](g *Generator, seq iter.Seq[T], m int) []T {
+	if g == nil {
+		g = std
+	}
+	// like make, but a negative size just means an empty sample
+	m = max(m, 0)
+	reservoir := make([]T, 0, m)
+	i := 0
+	for item := range seq {
+		if i < m {
+			reservoir = append(reservoir, item)
+		} else if j := g.r.Intn(i + 1); j < m {
+			reservoir[j] = item
+		}
+		i++
+	}
+	return reservoir
+}
+
+// SampleLines reservoir samples m lines from r, reading it once
+func (g *Generator) SampleLines(r io.Reader, m int) ([]string, error) {
+	if m < 0 {
+		return nil, fmt.Errorf("can't sample %v lines", m)
+	}
+	scanner := bufio.NewScanner(r)
+	lines := func(yield func(string) bool) {
+		for scanner.Scan() {
+			if !yield(scanner.Text()) {
+				return
+			}
+		}
+	}
+	sample := Reservoir(g, lines, m)
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return sample, nil
+}
@@ -0,0 +1,210 @@
+package random
+
+import (
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const (
+	sampleSeed   = 12
+	sampleTrials = 20000
+	sampleM      = 5
+	sampleN      = 20
+)
+
+// chiSquare returns the chi-square statistic of observed counts against the same
+// expected count in every bucket
+func chiSquare(observed []int, expected float64) float64 {
+	stat := 0.0
+	for _, o := range observed {
+		d := float64(o) - expected
+		stat += d * d / expected
+	}
+	return stat
+}
+
+// chiSquareCritical999 is the 99.9% point of the chi-square distribution with
+// sampleN-1 = 19 degrees of freedom. A fair sampler lands above it 1 time in 1000.
+const chiSquareCritical999 = 43.82
+
+// checkDistinct makes sure a sample is the right size, in range and distinct
+func checkDistinct(t *testing.T, sample []int, m, n int) {
+	if len(sample) != m {
+		t.Fatalf("sample has %v values, expected %v", len(sample), m)
+	}
+	sorted := slices.Clone(sample)
+	sort.Ints(sorted)
+	for i, v := range sorted {
+		if v < 0 || v >= n {
+			t.Fatalf("%v is out of range [0, %v)", v, n)
+		}
+		if i > 0 && v == sorted[i-1] {
+			t.Fatalf("%v is in the sample twice: %v", v, sample)
+		}
+	}
+}
+
+// testUniform runs a sampler many times and checks every value is picked about
+// m/n of the time
+func testUniform(t *testing.T, name string, sampler func(g *Generator) []int) {
+	g := NewSeeded(sampleSeed)
+	counts := make([]int, sampleN)
+	for i := 0; i < sampleTrials; i++ {
+		sample := sampler(g)
+		checkDistinct(t, sample, sampleM, sampleN)
+		for _, v := range sample {
+			counts[v]++
+		}
+	}
+	stat := chiSquare(counts, float64(sampleTrials*sampleM)/sampleN)
+	t.Logf("%v: chi-square %.2f (seed %v)", name, stat, g.Seed())
+	if stat > chiSquareCritical999 {
+		t.Errorf("%v: chi-square %.2f is over %v, counts %v", name, stat, chiSquareCritical999, counts)
+	}
+}
+
+func TestSampleSorted(t *testing.T) {
+	testUniform(t, "SampleSorted", func(g *Generator) []int {
+		sample, err := g.SampleSorted(sampleM, sampleN)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !sort.IntsAreSorted(sample) {
+			t.Fatalf("sample isn't sorted: %v", sample)
+		}
+		return sample
+	})
+}
+
+func TestSelectionSample(t *testing.T) {
+	testUniform(t, "SelectionSample", func(g *Generator) []int {
+		sample, err := g.SelectionSample(sampleM, sampleN)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !sort.IntsAreSorted(sample) {
+			t.Fatalf("sample isn't sorted: %v", sample)
+		}
+		return sample
+	})
+}
+
+func TestReservoir(t *testing.T) {
+	// a stream of 0..n-1 that the reservoir doesn't know the length of
+	stream := func(yield func(int) bool) {
+		for i := 0; i < sampleN; i++ {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+	testUniform(t, "Reservoir", func(g *Generator) []int {
+		return Reservoir(g, stream, sampleM)
+	})
+
+	if s := Reservoir(nil, stream, -1); len(s) != 0 {
+		t.Errorf("a negative size sampled %v", s)
+	}
+}
+
+// TestSubsetsUniform goes further than per-value counts: every one of the 10 ways
+// to pick 2 of 5 should show up equally often
+func TestSubsetsUniform(t *testing.T) {
+	// 99.9% point of chi-square with 9 degrees of freedom
+	const critical = 27.88
+	samplers := map[string]func(g *Generator) []int{
+		"SampleSorted": func(g *Generator) []int {
+			s, _ := g.SampleSorted(2, 5)
+			return s
+		},
+		"SelectionSample": func(g *Generator) []int {
+			s, _ := g.SelectionSample(2, 5)
+			return s
+		},
+	}
+	for name, sampler := range samplers {
+		g := NewSeeded(sampleSeed)
+		seen := make(map[[2]int]int)
+		for i := 0; i < sampleTrials; i++ {
+			s := sampler(g)
+			seen[[2]int{s[0], s[1]}]++
+		}
+		if len(seen) != 10 {
+			t.Fatalf("%v: saw %v different subsets, expected 10", name, len(seen))
+		}
+		counts := make([]int, 0, len(seen))
+		for _, c := range seen {
+			counts = append(counts, c)
+		}
+		if stat := chiSquare(counts, sampleTrials/10.0); stat > critical {
+			t.Errorf("%v: subset chi-square %.2f is over %v: %v", name, stat, critical, seen)
+		}
+	}
+}
+
+func TestSampleEdges(t *testing.T) {
+	for _, sampler := range []func(int, int) ([]int, error){SampleSorted, SelectionSample} {
+		if _, err := sampler(5, 3); err == nil {
+			t.Error("sampled 5 out of 3")
+		}
+		if _, err := sampler(-1, 3); err == nil {
+			t.Error("sampled -1 values")
+		}
+		all, err := sampler(10, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, v := range all {
+			if v != i {
+				t.Fatalf("sampling everything gave %v", all)
+			}
+		}
+		if none, _ := sampler(0, 10); len(none) != 0 {
+			t.Errorf("sampling nothing gave %v", none)
+		}
+	}
+
+	short := Reservoir(NewSeeded(sampleSeed), slices.Values([]int{1, 2}), 5)
+	if len(short) != 2 {
+		t.Errorf("reservoir over 2 items gave %v", short)
+	}
+}
+
+func TestSampleLines(t *testing.T) {
+	var input strings.Builder
+	for i := 0; i < 100; i++ {
+		input.WriteString(strconv.Itoa(i) + "\n")
+	}
+	lines, err := NewSeeded(sampleSeed).SampleLines(strings.NewReader(input.String()), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sample := make([]int, len(lines))
+	for i, line := range lines {
+		if sample[i], err = strconv.Atoi(line); err != nil {
+			t.Fatal(err)
+		}
+	}
+	checkDistinct(t, sample, 10, 100)
+
+	if _, err := SampleLines(strings.NewReader(input.String()), -1); err == nil {
+		t.Error("sampled -1 lines")
+	}
+}
+
+func BenchmarkSampleSorted(b *testing.B) {
+	g := NewSeeded(sampleSeed)
+	for i := 0; i < b.N; i++ {
+		g.SampleSorted(100, 1000000)
+	}
+}
+
+func BenchmarkSelectionSample(b *testing.B) {
+	g := NewSeeded(sampleSeed)
+	for i := 0; i < b.N; i++ {
+		g.SelectionSample(100, 1000000)
+	}
+}
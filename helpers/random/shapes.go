@@ -0,0 +1,129 @@
+package random
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+)
+
+// Real ID streams aren't uniformly shuffled. These generators make the skewed,
+// clustered and partly ordered inputs that sort and search benchmarks should also
+// be run against. All of them return values in 0..max-1 unless noted.
+
+// GenerateZipfIntegers draws count integers from 0..max-1 where value k shows up
+// proportional to 1/(k+1)^s, so 0 is the most common, then 1, and so on. s has to
+// be > 1; around 1.1 looks like real popularity data.
+func (g *Generator) GenerateZipfIntegers(count, max int, s float64) ([]int, error) {
+	if s <= 1 {
+		return nil, errors.New("zipf exponent must be > 1")
+	}
+	if max < 1 {
+		return nil, errors.New("max must be positive")
+	}
+	zipf := rand.NewZipf(g.r, s, 1, uint64(max-1))
+	list := make([]int, count)
+	for i := range list {
+		list[i] = int(zipf.Uint64())
+	}
+	return list, nil
+}
+
+// GenerateNormalIntegers draws count integers from a normal distribution with the
+// given mean and standard deviation, rounded and clamped to 0..max-1
+func (g *Generator) GenerateNormalIntegers(count, max int, mean, stddev float64) []int {
+	list := make([]int, count)
+	for i := range list {
+		v := math.Round(g.r.NormFloat64()*stddev + mean)
+		list[i] = int(math.Max(0, math.Min(float64(max-1), v)))
+	}
+	return list
+}
+
+// GenerateClusteredIntegers picks clusters random ranges of width values inside
+// 0..max-1, then draws count integers uniformly from those ranges. It looks like
+// IDs handed out in batches.
+func (g *Generator) GenerateClusteredIntegers(count, max, clusters, width int) ([]int, error) {
+	if clusters < 1 || width < 1 || width > max {
+		return nil, errors.New("need at least one cluster, and a width between 1 and max")
+	}
+	starts := make([]int, clusters)
+	for i := range starts {
+		starts[i] = g.r.Intn(max - width + 1)
+	}
+	list := make([]int, count)
+	for i := range list {
+		list[i] = starts[g.r.Intn(clusters)] + g.r.Intn(width)
+	}
+	return list, nil
+}
+
+// GenerateNearlySortedIntegers returns 0..count-1 in order, then swaps
+// disorder*count random pairs of neighbors. A disorder of 0.01 is 1% of the
+// list out of place by a step or so.
+func (g *Generator) GenerateNearlySortedIntegers(count int, disorder float64) []int {
+	list := make([]int, count)
+	for i := range list {
+		list[i] = i
+	}
+	if count < 2 {
+		return list
+	}
+	swaps := int(disorder * float64(count))
+	for i := 0; i < swaps; i++ {
+		j := g.r.Intn(count - 1)
+		list[j], list[j+1] = list[j+1], list[j]
+	}
+	return list
+}
+
+// GenerateReverseSortedIntegers returns count-1 down to 0
+func GenerateReverseSortedIntegers(count int) []int {
+	list := make([]int, count)
+	for i := range list {
+		list[i] = count - 1 - i
+	}
+	return list
+}
+
+// AddDuplicates makes roughly rate of list into duplicates by overwriting each
+// element, with probability rate, with the one before it. Copying the neighbor
+// keeps the shape: sorted input stays sorted, clusters stay clustered.
+// list is modified in place and returned.
+func (g *Generator) AddDuplicates(list []int, rate float64) []int {
+	for i := 1; i < len(list); i++ {
+		if g.r.Float64() < rate {
+			list[i] = list[i-1]
+		}
+	}
+	return list
+}
+
+// GenerateZipfIntegers draws Zipf-distributed integers with the default generator.
+// See Generator.GenerateZipfIntegers.
+func GenerateZipfIntegers(count, max int, s float64) ([]int, error) {
+	return std.GenerateZipfIntegers(count, max, s)
+}
+
+// GenerateNormalIntegers draws normally distributed integers with the default
+// generator. See Generator.GenerateNormalIntegers.
+func GenerateNormalIntegers(count, max int, mean, stddev float64) []int {
+	return std.GenerateNormalIntegers(count, max, mean, stddev)
+}
+
+// GenerateClusteredIntegers draws clustered integers with the default generator.
+// See Generator.GenerateClusteredIntegers.
+func GenerateClusteredIntegers(count, max, clusters, width int) ([]int, error) {
+	return std.GenerateClusteredIntegers(count, max, clusters, width)
+}
+
+// GenerateNearlySortedIntegers makes a nearly sorted list with the default
+// generator. See Generator.GenerateNearlySortedIntegers.
+func GenerateNearlySortedIntegers(count int, disorder float64) []int {
+	return std.GenerateNearlySortedIntegers(count, disorder)
+}
+
+// AddDuplicates adds duplicates to list with the default generator.
+// See Generator.AddDuplicates.
+func AddDuplicates(list []int, rate float64) []int {
+	return std.AddDuplicates(list, rate)
+}
@@ -0,0 +1,174 @@
+package random
+
+import (
+	"math"
+	"sort"
+	"testing"
+)
+
+const (
+	shapeSeed  = 40
+	shapeCount = 20000
+	shapeMax   = 1000
+)
+
+func TestGenerateZipfIntegers(t *testing.T) {
+	list, err := NewSeeded(shapeSeed).GenerateZipfIntegers(shapeCount, shapeMax, 1.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := make([]int, shapeMax)
+	for _, v := range list {
+		if v < 0 || v >= shapeMax {
+			t.Fatalf("%v is out of range", v)
+		}
+		counts[v]++
+	}
+	// with s = 1.5, 0 should be about 2^1.5 = 2.8 times as common as 1
+	ratio := float64(counts[0]) / float64(counts[1])
+	if ratio < 2.4 || ratio > 3.3 {
+		t.Errorf("count of 0 over count of 1 is %.2f, expected about 2.83", ratio)
+	}
+	if counts[1] <= counts[5] || counts[5] <= counts[50] {
+		t.Errorf("counts aren't falling off: %v %v %v", counts[1], counts[5], counts[50])
+	}
+
+	if _, err := GenerateZipfIntegers(10, shapeMax, 1); err == nil {
+		t.Error("accepted s = 1")
+	}
+}
+
+func TestGenerateNormalIntegers(t *testing.T) {
+	list := NewSeeded(shapeSeed).GenerateNormalIntegers(shapeCount, shapeMax, 500, 50)
+	sum, sumSquares := 0.0, 0.0
+	for _, v := range list {
+		if v < 0 || v >= shapeMax {
+			t.Fatalf("%v is out of range", v)
+		}
+		sum += float64(v)
+		sumSquares += float64(v) * float64(v)
+	}
+	mean := sum / shapeCount
+	stddev := math.Sqrt(sumSquares/shapeCount - mean*mean)
+	if math.Abs(mean-500) > 2 || math.Abs(stddev-50) > 2 {
+		t.Errorf("mean %.2f and stddev %.2f, expected 500 and 50", mean, stddev)
+	}
+
+	// way off the end gets clamped
+	for _, v := range GenerateNormalIntegers(100, 10, 1000, 1) {
+		if v != 9 {
+			t.Fatalf("expected everything clamped to 9, got %v", v)
+		}
+	}
+}
+
+func TestGenerateClusteredIntegers(t *testing.T) {
+	const clusters, width = 5, 20
+	list, err := NewSeeded(shapeSeed).GenerateClusteredIntegers(shapeCount, shapeMax, clusters, width)
+	if err != nil {
+		t.Fatal(err)
+	}
+	distinct := make(map[int]bool)
+	for _, v := range list {
+		if v < 0 || v >= shapeMax {
+			t.Fatalf("%v is out of range", v)
+		}
+		distinct[v] = true
+	}
+	if len(distinct) > clusters*width {
+		t.Errorf("%v distinct values can't fit in %v clusters of %v", len(distinct), clusters, width)
+	}
+
+	if _, err := GenerateClusteredIntegers(10, 10, 1, 11); err == nil {
+		t.Error("accepted a cluster wider than the range")
+	}
+}
+
+// inversions counts pairs that are out of order, the usual measure of sortedness
+func inversions(list []int) (count int) {
+	for i := range list {
+		for j := i + 1; j < len(list); j++ {
+			if list[i] > list[j] {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+func TestGenerateNearlySortedIntegers(t *testing.T) {
+	const count = 2000
+	list := NewSeeded(shapeSeed).GenerateNearlySortedIntegers(count, 0.01)
+
+	seen := make([]bool, count)
+	for _, v := range list {
+		if seen[v] {
+			t.Fatalf("%v showed up twice", v)
+		}
+		seen[v] = true
+	}
+	// each neighbor swap adds or removes exactly one inversion
+	if inv := inversions(list); inv == 0 || inv > 20 {
+		t.Errorf("%v inversions, expected between 1 and 20", inv)
+	}
+
+	if inv := inversions(GenerateNearlySortedIntegers(count, 0)); inv != 0 {
+		t.Errorf("no disorder still had %v inversions", inv)
+	}
+}
+
+func TestGenerateReverseSortedIntegers(t *testing.T) {
+	list := GenerateReverseSortedIntegers(100)
+	if list[0] != 99 || list[99] != 0 || !sort.IsSorted(sort.Reverse(sort.IntSlice(list))) {
+		t.Errorf("not reverse sorted: %v", list)
+	}
+}
+
+func TestAddDuplicates(t *testing.T) {
+	g := NewSeeded(shapeSeed)
+	list := make([]int, shapeCount)
+	for i := range list {
+		list[i] = i
+	}
+	g.AddDuplicates(list, 0.25)
+
+	if !sort.IntsAreSorted(list) {
+		t.Error("duplicates broke the sorted order")
+	}
+	dups := 0
+	for i := 1; i < len(list); i++ {
+		if list[i] == list[i-1] {
+			dups++
+		}
+	}
+	if rate := float64(dups) / shapeCount; math.Abs(rate-0.25) > 0.02 {
+		t.Errorf("duplicate rate is %.3f, expected 0.25", rate)
+	}
+}
+
+// BenchmarkSortShapes runs sort.Ints over each input shape, which is the point of
+// having them. Compare against the uniform shuffle the other benchmarks use.
+func BenchmarkSortShapes(b *testing.B) {
+	const n = 100000
+	g := NewSeeded(shapeSeed)
+	zipf, _ := g.GenerateZipfIntegers(n, n, 1.1)
+	clustered, _ := g.GenerateClusteredIntegers(n, n*10, 10, 1000)
+	shapes := map[string][]int{
+		"Uniform":           g.GenerateUniqueRandomIntegers(n),
+		"Zipf":              zipf,
+		"Normal":            g.GenerateNormalIntegers(n, n, n/2, n/10),
+		"Clustered":         clustered,
+		"NearlySorted":      g.GenerateNearlySortedIntegers(n, 0.01),
+		"ReverseSorted":     GenerateReverseSortedIntegers(n),
+		"UniformDuplicates": g.AddDuplicates(g.GenerateUniqueRandomIntegers(n), 0.5),
+	}
+	for name, shape := range shapes {
+		b.Run(name, func(b *testing.B) {
+			list := make([]int, len(shape))
+			for i := 0; i < b.N; i++ {
+				copy(list, shape)
+				sort.Ints(list)
+			}
+		})
+	}
+}
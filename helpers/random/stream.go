@@ -0,0 +1,145 @@
+package random
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+	"strconv"
+)
+
+// GenerateUniqueRandomIntegers shuffles the whole list in memory, which is fine for
+// tests but not for the 10 million 7 digit phone numbers Column 1 talks about.
+// A Permutation computes the i-th element of a random permutation on its own, so
+// writing out a shuffled range takes constant memory.
+
+// feistelRounds is how many rounds of mixing each lookup does. 4 is the minimum
+// for a Feistel network to look like a random permutation; a couple extra is cheap.
+const feistelRounds = 6
+
+// Permutation is a pseudorandom permutation of 0..n-1 that can be evaluated at any
+// index without storing anything but the keys.
+//
+// It's a small Feistel network over the smallest even number of bits that covers
+// n. That's a permutation of a power-of-4 sized range, at most 4n; anything that
+// lands at or above n is fed back in until it doesn't ("cycle walking"), which keeps
+// it a permutation of 0..n-1.
+type Permutation struct {
+	n        uint64
+	halfBits uint
+	halfMask uint64
+	keys     [feistelRounds]uint64
+}
+
+// NewPermutation makes a random permutation of 0..n-1 with the default generator
+func NewPermutation(n uint64) *Permutation {
+	return std.NewPermutation(n)
+}
+
+// NewPermutation makes a random permutation of 0..n-1 keyed from g, so the same
+// seed gives the same permutation
+func (g *Generator) NewPermutation(n uint64) *Permutation {
+	half := uint(bits.Len64(n-1)+1) / 2
+	if n <= 1 {
+		half = 1
+	}
+	p := &Permutation{
+		n:        n,
+		halfBits: half,
+		halfMask: 1<<half - 1,
+	}
+	for i := range p.keys {
+		p.keys[i] = g.r.Uint64()
+	}
+	return p
+}
+
+// Len is n, the size of the range being permuted
+func (p *Permutation) Len() uint64 {
+	return p.n
+}
+
+// At returns the element at position i, which must be < Len()
+func (p *Permutation) At(i uint64) uint64 {
+	if i >= p.n {
+		panic(fmt.Sprintf("permutation index %v out of range [0, %v)", i, p.n))
+	}
+	v := p.encrypt(i)
+	for v >= p.n {
+		v = p.encrypt(v)
+	}
+	return v
+}
+
+func (p *Permutation) encrypt(v uint64) uint64 {
+	left, right := v>>p.halfBits, v&p.halfMask
+	for _, key := range p.keys {
+		left, right = right, left^(mix(right^key)&p.halfMask)
+	}
+	return left<<p.halfBits | right
+}
+
+// mix is the splitmix64 finalizer, used as the Feistel round function
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// WriteUniqueText writes count unique random integers from 0..n-1 to w, one per
+// line, in the format the bitmap sorts read. Memory use doesn't depend on count.
+func (g *Generator) WriteUniqueText(w io.Writer, count, n uint64) error {
+	if count > n {
+		return fmt.Errorf("can't write %v unique integers from a range of %v", count, n)
+	}
+	p := g.NewPermutation(n)
+	writer := bufio.NewWriter(w)
+	buf := make([]byte, 0, 24)
+	for i := uint64(0); i < count; i++ {
+		buf = strconv.AppendUint(buf[:0], p.At(i), 10)
+		buf = append(buf, '\n')
+		if _, err := writer.Write(buf); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+// WriteUniqueBinary writes count unique random integers from 0..n-1 to w as
+// big-endian uint32s, the format helpers/binary and search use. n can't be more
+// than 2^32.
+func (g *Generator) WriteUniqueBinary(w io.Writer, count, n uint64) error {
+	if count > n {
+		return fmt.Errorf("can't write %v unique integers from a range of %v", count, n)
+	}
+	if n > math.MaxUint32+1 {
+		return fmt.Errorf("a range of %v doesn't fit in uint32s", n)
+	}
+	p := g.NewPermutation(n)
+	writer := bufio.NewWriter(w)
+	var buf [4]byte
+	for i := uint64(0); i < count; i++ {
+		binary.BigEndian.PutUint32(buf[:], uint32(p.At(i)))
+		if _, err := writer.Write(buf[:]); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+// WriteUniqueText writes count unique random integers from 0..n-1 to w with the
+// default generator. See Generator.WriteUniqueText.
+func WriteUniqueText(w io.Writer, count, n uint64) error {
+	return std.WriteUniqueText(w, count, n)
+}
+
+// WriteUniqueBinary writes count unique random integers from 0..n-1 to w with the
+// default generator. See Generator.WriteUniqueBinary.
+func WriteUniqueBinary(w io.Writer, count, n uint64) error {
+	return std.WriteUniqueBinary(w, count, n)
+}
@@ -0,0 +1,124 @@
+package random
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strconv"
+	"testing"
+)
+
+func TestPermutation(t *testing.T) {
+	// sizes around powers of two and four, where the cycle walking kicks in
+	for _, n := range []uint64{1, 2, 3, 4, 5, 15, 16, 17, 63, 64, 65, 1000, 4096, 10007} {
+		p := NewSeeded(int64(n)).NewPermutation(n)
+		seen := make([]bool, n)
+		for i := uint64(0); i < n; i++ {
+			v := p.At(i)
+			if v >= n {
+				t.Fatalf("n=%v: At(%v) = %v is out of range", n, i, v)
+			}
+			if seen[v] {
+				t.Fatalf("n=%v: %v came up twice", n, v)
+			}
+			seen[v] = true
+		}
+	}
+}
+
+func TestPermutationReproducible(t *testing.T) {
+	a, b := NewSeeded(7).NewPermutation(1000), NewSeeded(7).NewPermutation(1000)
+	c := NewSeeded(8).NewPermutation(1000)
+	same := 0
+	for i := uint64(0); i < 1000; i++ {
+		if a.At(i) != b.At(i) {
+			t.Fatalf("same seed, different permutation at %v", i)
+		}
+		if a.At(i) == c.At(i) {
+			same++
+		}
+	}
+	// a random pair of permutations agree in about 1 place
+	if same > 20 {
+		t.Errorf("seeds 7 and 8 agree in %v of 1000 places", same)
+	}
+}
+
+// TestPermutationPositions checks the shuffle isn't lopsided: across many keys,
+// position 0 should hold each value about equally often
+func TestPermutationPositions(t *testing.T) {
+	const n, trials = 20, 20000
+	// 99.9% point of chi-square with 19 degrees of freedom
+	const critical = 43.82
+
+	g := NewSeeded(3)
+	counts := make([]int, n)
+	for i := 0; i < trials; i++ {
+		counts[g.NewPermutation(n).At(0)]++
+	}
+	if stat := chiSquare(counts, trials/n); stat > critical {
+		t.Errorf("chi-square %.2f is over %v: %v", stat, critical, counts)
+	}
+}
+
+func TestWriteUniqueText(t *testing.T) {
+	const count, n = 5000, 10000
+	var buf bytes.Buffer
+	if err := NewSeeded(1).WriteUniqueText(&buf, count, n); err != nil {
+		t.Fatal(err)
+	}
+
+	seen := make(map[int]bool)
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		v, err := strconv.Atoi(scanner.Text())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v < 0 || v >= n || seen[v] {
+			t.Fatalf("%v is out of range or a duplicate", v)
+		}
+		seen[v] = true
+	}
+	if len(seen) != count {
+		t.Errorf("wrote %v integers, expected %v", len(seen), count)
+	}
+
+	if err := WriteUniqueText(io.Discard, 11, 10); err == nil {
+		t.Error("wrote 11 unique integers out of 10")
+	}
+}
+
+func TestWriteUniqueBinary(t *testing.T) {
+	const count, n = 5000, 5000
+	var buf bytes.Buffer
+	if err := NewSeeded(1).WriteUniqueBinary(&buf, count, n); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != count*4 {
+		t.Fatalf("wrote %v bytes, expected %v", buf.Len(), count*4)
+	}
+	seen := make([]bool, n)
+	for i := 0; i < count; i++ {
+		v := binary.BigEndian.Uint32(buf.Bytes()[i*4:])
+		if v >= n || seen[v] {
+			t.Fatalf("%v is out of range or a duplicate", v)
+		}
+		seen[v] = true
+	}
+
+	if err := WriteUniqueBinary(io.Discard, 1, 1<<33); err == nil {
+		t.Error("wrote a range that doesn't fit in uint32")
+	}
+}
+
+// BenchmarkWriteUniqueText writes the Column 1 input: 7 digit numbers. Memory stays
+// flat no matter how big count gets.
+func BenchmarkWriteUniqueText(b *testing.B) {
+	g := NewSeeded(1)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		g.WriteUniqueText(io.Discard, 100000, 10000000)
+	}
+}
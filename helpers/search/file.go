@@ -0,0 +1,223 @@
+package search
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+// TextFile is a sorted file of newline-delimited integers, the same format the
+// bitmap sorts write out. Lookups seek around the file instead of loading it.
+type TextFile struct {
+	fh   *os.File
+	size int64
+}
+
+// OpenTextFile opens a sorted text file for searching. Caller must Close it.
+func OpenTextFile(filename string) (*TextFile, error) {
+	fh, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	info, err := fh.Stat()
+	if err != nil {
+		fh.Close()
+		return nil, err
+	}
+	return &TextFile{fh: fh, size: info.Size()}, nil
+}
+
+// Close closes the underlying file
+func (f *TextFile) Close() error {
+	return f.fh.Close()
+}
+
+// Size is the length of the file in bytes
+func (f *TextFile) Size() int64 {
+	return f.size
+}
+
+// Contains reports whether val is one of the lines in the file
+func (f *TextFile) Contains(val int) (bool, error) {
+	_, next, err := f.LowerBound(val)
+	if err == io.EOF {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return next == val, nil
+}
+
+// LowerBound finds the first line whose value is >= val. It returns the byte offset
+// where that line starts along with the value on it. If every value in the file is
+// smaller than val, the offset is the file size and err is io.EOF.
+//
+// The search is done on byte offsets: pick the middle byte, walk forward to the next
+// line boundary, and read the number there. lo and hi are always line starts.
+// Blank lines are skipped, the same as LoadSortedSet does, so a trailing "\n\n"
+// doesn't break anything.
+func (f *TextFile) LowerBound(val int) (offset int64, next int, err error) {
+	lo, hi := int64(0), f.size
+
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		start, err := f.lineStart(mid)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		// no line begins in [mid, hi), so the range is down to the line at lo
+		if start >= hi {
+			start = lo
+		}
+
+		v, at, end, err := f.lineAt(start)
+		if err != nil && err != io.EOF {
+			return 0, 0, err
+		}
+		// only blank lines before hi, they go with whatever hi is
+		if err == io.EOF || at >= hi {
+			hi = start
+			continue
+		}
+		if v < val {
+			lo = end
+		} else {
+			hi = start
+		}
+	}
+
+	next, offset, _, err = f.lineAt(lo)
+	if err == io.EOF {
+		return f.size, 0, io.EOF
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	return offset, next, nil
+}
+
+// lineStart returns the offset of the first line starting at or after pos
+func (f *TextFile) lineStart(pos int64) (int64, error) {
+	if pos == 0 {
+		return 0, nil
+	}
+	// back up one byte so a pos that's already a line start is found
+	r := bufio.NewReader(io.NewSectionReader(f.fh, pos-1, f.size-pos+1))
+	skipped, err := r.ReadBytes('\n')
+	if err == io.EOF {
+		return f.size, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return pos - 1 + int64(len(skipped)), nil
+}
+
+// lineAt parses the integer on the first non-blank line starting at or after pos.
+// It returns where that line starts and the offset of the line after it, or io.EOF
+// if there are only blank lines left.
+func (f *TextFile) lineAt(pos int64) (val int, start, end int64, err error) {
+	r := bufio.NewReader(io.NewSectionReader(f.fh, pos, f.size-pos))
+	for start = pos; start < f.size; start = end {
+		line, err := r.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return 0, 0, 0, err
+		}
+		end = start + int64(len(line))
+
+		trimmed := bytes.TrimSpace(line)
+		if len(trimmed) == 0 {
+			continue
+		}
+		val, err = strconv.Atoi(string(trimmed))
+		if err != nil {
+			return 0, 0, 0, fmt.Errorf("bad line at offset %v: %v", start, err)
+		}
+		return val, start, end, nil
+	}
+	return 0, f.size, f.size, io.EOF
+}
+
+// BinaryFile is a sorted file of big-endian uint32s, the format that
+// helpers/binary.MakeBinaryFile writes.
+type BinaryFile struct {
+	fh    *os.File
+	count int64
+}
+
+// OpenBinaryFile opens a sorted binary file for searching. Caller must Close it.
+func OpenBinaryFile(filename string) (*BinaryFile, error) {
+	fh, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	info, err := fh.Stat()
+	if err != nil {
+		fh.Close()
+		return nil, err
+	}
+	if info.Size()%4 != 0 {
+		fh.Close()
+		return nil, fmt.Errorf("%v is %v bytes, not a multiple of 4", filename, info.Size())
+	}
+	return &BinaryFile{fh: fh, count: info.Size() / 4}, nil
+}
+
+// Close closes the underlying file
+func (f *BinaryFile) Close() error {
+	return f.fh.Close()
+}
+
+// Len is the number of integers in the file
+func (f *BinaryFile) Len() int64 {
+	return f.count
+}
+
+// At reads the integer at index i
+func (f *BinaryFile) At(i int64) (uint32, error) {
+	if i < 0 || i >= f.count {
+		return 0, fmt.Errorf("index %v out of range [0, %v)", i, f.count)
+	}
+	var buf [4]byte
+	if _, err := f.fh.ReadAt(buf[:], i*4); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint32(buf[:]), nil
+}
+
+// Contains reports whether val is in the file
+func (f *BinaryFile) Contains(val uint32) (bool, error) {
+	i, err := f.LowerBound(val)
+	if err != nil || i == f.count {
+		return false, err
+	}
+	v, err := f.At(i)
+	if err != nil {
+		return false, err
+	}
+	return v == val, nil
+}
+
+// LowerBound returns the index of the first integer >= val, or Len() if there isn't one
+func (f *BinaryFile) LowerBound(val uint32) (int64, error) {
+	lo, hi := int64(0), f.count
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		v, err := f.At(mid)
+		if err != nil {
+			return 0, err
+		}
+		if v < val {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	return lo, nil
+}
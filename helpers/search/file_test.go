@@ -0,0 +1,195 @@
+package search
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/Stantheman/pearls/helpers/binary"
+	"github.com/Stantheman/pearls/helpers/random"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const (
+	sortedTextFile   = "sorted.txt"
+	sortedBinaryFile = "sorted.bin"
+	sortedCount      = 5000
+)
+
+// writeSortedText writes ints out the same way the bitmap sorts do
+func writeSortedText(filename string, ints []int) error {
+	fh, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	writer := bufio.NewWriter(fh)
+	for _, v := range ints {
+		if _, err := fmt.Fprintf(writer, "%v\n", v); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+func TestTextFileLowerBound(t *testing.T) {
+	ints := random.GenerateIncreasingRandomIntegers(sortedCount)
+	if err := writeSortedText(sortedTextFile, ints); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(sortedTextFile)
+
+	f, err := OpenTextFile(sortedTextFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	max := ints[len(ints)-1]
+	for val := -1; val <= max+1; val++ {
+		expected := sort.SearchInts(ints, val)
+
+		_, next, err := f.LowerBound(val)
+		if expected == len(ints) {
+			if err != io.EOF {
+				t.Fatalf("%v: expected io.EOF, got %v (%v)", val, err, next)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%v: %v", val, err)
+		}
+		if next != ints[expected] {
+			t.Fatalf("%v: lower bound is %v, expected %v", val, next, ints[expected])
+		}
+
+		found, err := f.Contains(val)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if found != (ints[expected] == val) {
+			t.Fatalf("%v: Contains said %v", val, found)
+		}
+	}
+}
+
+func TestTextFileEdges(t *testing.T) {
+	// empty, single line, no trailing newline, and blank lines, which
+	// LoadSortedSet skips so this has to as well
+	cases := map[string][]int{
+		"":                   {},
+		"7\n":                {7},
+		"1\n2\n3":            {1, 2, 3},
+		"-5\n10\n":           {-5, 10},
+		"1\n2\n\n":           {1, 2},
+		"\n\n":               {},
+		"\n1\n\n\n4\n \n9\n": {1, 4, 9},
+	}
+	for contents, ints := range cases {
+		if err := os.WriteFile(sortedTextFile, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+		f, err := OpenTextFile(sortedTextFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for val := -6; val < 12; val++ {
+			found, err := f.Contains(val)
+			if err != nil {
+				t.Fatalf("%q, %v: %v", contents, val, err)
+			}
+			i := sort.SearchInts(ints, val)
+			if expected := i < len(ints) && ints[i] == val; found != expected {
+				t.Errorf("%q: Contains(%v) is %v, expected %v", contents, val, found, expected)
+			}
+			// the offset has to point at the value's own line, not a blank before it
+			offset, next, err := f.LowerBound(val)
+			if i == len(ints) {
+				if err != io.EOF {
+					t.Errorf("%q: LowerBound(%v) should be io.EOF, got %v", contents, val, err)
+				}
+				continue
+			}
+			if err != nil || next != ints[i] || !strings.HasPrefix(contents[offset:], strconv.Itoa(ints[i])) {
+				t.Errorf("%q: LowerBound(%v) = %v, %v, %v", contents, val, offset, next, err)
+			}
+		}
+		f.Close()
+	}
+	os.Remove(sortedTextFile)
+}
+
+func TestBinaryFileLowerBound(t *testing.T) {
+	increasing := random.GenerateIncreasingRandomIntegers(sortedCount)
+	ints := make([]uint32, len(increasing))
+	for i, v := range increasing {
+		ints[i] = uint32(v)
+	}
+	if err := binary.MakeBinaryFile(sortedBinaryFile, ints); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(sortedBinaryFile)
+
+	f, err := OpenBinaryFile(sortedBinaryFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if f.Len() != sortedCount {
+		t.Fatalf("Len is %v, expected %v", f.Len(), sortedCount)
+	}
+
+	for val := 0; val <= increasing[len(increasing)-1]+1; val++ {
+		expected := sort.SearchInts(increasing, val)
+		i, err := f.LowerBound(uint32(val))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i != int64(expected) {
+			t.Fatalf("%v: lower bound is %v, expected %v", val, i, expected)
+		}
+
+		found, err := f.Contains(uint32(val))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if found != (expected < len(increasing) && increasing[expected] == val) {
+			t.Fatalf("%v: Contains said %v", val, found)
+		}
+	}
+}
+
+func TestBinaryFileBadSize(t *testing.T) {
+	if err := os.WriteFile(sortedBinaryFile, []byte{1, 2, 3}, 0644); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(sortedBinaryFile)
+
+	if _, err := OpenBinaryFile(sortedBinaryFile); err == nil {
+		t.Error("opened a 3 byte file without complaining")
+	}
+}
+
+func BenchmarkTextFileContains(b *testing.B) {
+	ints := random.GenerateIncreasingRandomIntegers(count)
+	if err := writeSortedText(sortedTextFile, ints); err != nil {
+		b.Fatal(err)
+	}
+	defer os.Remove(sortedTextFile)
+
+	f, err := OpenTextFile(sortedTextFile)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer f.Close()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.Contains(ints[i%len(ints)])
+	}
+}
@@ -0,0 +1,209 @@
+package search
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SortedSet is an immutable set of integers built for fast membership checks.
+//
+// Plain binary search jumps all over the array, so every probe past the first few is
+// a cache miss. SortedSet also keeps the values in Eytzinger (breadth-first) order,
+// where the children of slot k are at 2k and 2k+1. The first several levels of the
+// search all sit next to each other in memory, and the loop doesn't need a branch.
+type SortedSet struct {
+	// sorted is the values in increasing order, for ranges
+	sorted []int
+	// eytz is the BFS layout, 1-indexed so the child math works. eytz[0] is unused
+	eytz []int
+	// rank maps an eytz slot back to its position in sorted
+	rank []int
+}
+
+// NewSortedSet builds a set from values. values isn't modified, and duplicates are dropped.
+func NewSortedSet(values []int) *SortedSet {
+	sorted := make([]int, len(values))
+	copy(sorted, values)
+	if !sort.IntsAreSorted(sorted) {
+		sort.Ints(sorted)
+	}
+
+	// squash out duplicates
+	unique := 0
+	for i, v := range sorted {
+		if i == 0 || v != sorted[unique-1] {
+			sorted[unique] = v
+			unique++
+		}
+	}
+	sorted = sorted[:unique]
+
+	s := &SortedSet{
+		sorted: sorted,
+		eytz:   make([]int, len(sorted)+1),
+		rank:   make([]int, len(sorted)+1),
+	}
+	s.layout(0, 1)
+	return s
+}
+
+// LoadSortedSet builds a set from a file of newline-delimited integers, like the
+// output of the bitmap sorts
+func LoadSortedSet(filename string) (*SortedSet, error) {
+	fh, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer fh.Close()
+
+	values := make([]int, 0)
+	scanner := bufio.NewScanner(fh)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		val, err := strconv.Atoi(line)
+		if err != nil {
+			return nil, fmt.Errorf("%v isn't a valid integer: %v", line, err)
+		}
+		values = append(values, val)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return NewSortedSet(values), nil
+}
+
+// layout fills eytz with an in-order walk of the implicit tree, which hands out the
+// sorted values in order. i is the next sorted value to place, k is the current slot.
+func (s *SortedSet) layout(i, k int) int {
+	if k < len(s.eytz) {
+		i = s.layout(i, 2*k)
+		s.eytz[k] = s.sorted[i]
+		s.rank[k] = i
+		i++
+		i = s.layout(i, 2*k+1)
+	}
+	return i
+}
+
+// Len is the number of values in the set
+func (s *SortedSet) Len() int {
+	return len(s.sorted)
+}
+
+// Values returns the set in increasing order. The caller must not modify it.
+func (s *SortedSet) Values() []int {
+	return s.sorted
+}
+
+// lowerBound returns the position in sorted of the first value >= v, or Len()
+func (s *SortedSet) lowerBound(v int) int {
+	n := len(s.eytz) - 1
+	k := 1
+	for k <= n {
+		k = 2*k + boolToInt(s.eytz[k] < v)
+	}
+	k = lastLeftTurn(k)
+	if k == 0 {
+		return len(s.sorted)
+	}
+	return s.rank[k]
+}
+
+// lastLeftTurn backs a finished search path up to the answer. The path ends with a
+// run of right turns after the last left turn, and that left turn was taken at the
+// node we want, so shift off the trailing ones and then the zero.
+func lastLeftTurn(k int) int {
+	for k&1 == 1 {
+		k >>= 1
+	}
+	return k >> 1
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// Contains reports whether v is in the set
+func (s *SortedSet) Contains(v int) bool {
+	i := s.lowerBound(v)
+	return i < len(s.sorted) && s.sorted[i] == v
+}
+
+// Range returns every value in [lo, hi). The result shares memory with the set and
+// must not be modified.
+func (s *SortedSet) Range(lo, hi int) []int {
+	if hi <= lo {
+		return nil
+	}
+	return s.sorted[s.lowerBound(lo):s.lowerBound(hi)]
+}
+
+// Nearest returns the value in the set closest to v. Ties go to the smaller value.
+// ok is false if the set is empty.
+func (s *SortedSet) Nearest(v int) (nearest int, ok bool) {
+	if len(s.sorted) == 0 {
+		return 0, false
+	}
+	i := s.lowerBound(v)
+	if i == len(s.sorted) {
+		return s.sorted[i-1], true
+	}
+	if i == 0 || s.sorted[i] == v {
+		return s.sorted[i], true
+	}
+	below, above := s.sorted[i-1], s.sorted[i]
+	// compare distances as uint to dodge overflow on huge gaps
+	if uint(v-below) <= uint(above-v) {
+		return below, true
+	}
+	return above, true
+}
+
+// batchWidth is how many searches ContainsBatch runs side by side
+const batchWidth = 8
+
+// ContainsBatch checks a batch of values at once. The searches are interleaved so
+// the memory loads for several lookups are in flight at the same time instead of
+// waiting on each other.
+func (s *SortedSet) ContainsBatch(values []int) []bool {
+	found := make([]bool, len(values))
+	n := len(s.eytz) - 1
+
+	var ks [batchWidth]int
+	for start := 0; start < len(values); start += batchWidth {
+		group := values[start:]
+		if len(group) > batchWidth {
+			group = group[:batchWidth]
+		}
+		for j := range group {
+			ks[j] = 1
+		}
+
+		// every search takes the same number of steps give or take the last level
+		for done := false; !done; {
+			done = true
+			for j, v := range group {
+				if ks[j] <= n {
+					ks[j] = 2*ks[j] + boolToInt(s.eytz[ks[j]] < v)
+					done = false
+				}
+			}
+		}
+
+		for j, v := range group {
+			k := lastLeftTurn(ks[j])
+			found[start+j] = k != 0 && s.eytz[k] == v
+		}
+	}
+	return found
+}
@@ -0,0 +1,164 @@
+package search
+
+import (
+	"github.com/Stantheman/pearls/helpers/random"
+	"os"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+const setSize = 100000
+
+func TestSortedSet(t *testing.T) {
+	// try every shape of tree from empty to a few full levels
+	for size := 0; size < 70; size++ {
+		values := random.GenerateIncreasingRandomIntegers(size)
+		set := NewSortedSet(values)
+
+		if set.Len() != size {
+			t.Fatalf("size %v: Len is %v", size, set.Len())
+		}
+		if size > 0 && !reflect.DeepEqual(set.Values(), values) {
+			t.Fatalf("size %v: values are %v, expected %v", size, set.Values(), values)
+		}
+
+		max := 0
+		if size > 0 {
+			max = values[size-1]
+		}
+		queries := make([]int, 0)
+		for v := -2; v < max+3; v++ {
+			queries = append(queries, v)
+		}
+		batch := set.ContainsBatch(queries)
+
+		for q, v := range queries {
+			i := sort.SearchInts(values, v)
+			expected := i < size && values[i] == v
+			if res := set.Contains(v); res != expected {
+				t.Fatalf("size %v: Contains(%v) is %v, expected %v", size, v, res, expected)
+			}
+			if batch[q] != expected {
+				t.Fatalf("size %v: ContainsBatch for %v is %v, expected %v", size, v, batch[q], expected)
+			}
+		}
+	}
+}
+
+func TestSortedSetDuplicates(t *testing.T) {
+	set := NewSortedSet([]int{5, 3, 5, 1, 3, 3, 9})
+	if expected := []int{1, 3, 5, 9}; !reflect.DeepEqual(set.Values(), expected) {
+		t.Errorf("values are %v, expected %v", set.Values(), expected)
+	}
+}
+
+func TestSortedSetRange(t *testing.T) {
+	set := NewSortedSet([]int{1, 3, 5, 7, 9, 11})
+	tests := []struct {
+		lo, hi   int
+		expected []int
+	}{
+		{0, 100, []int{1, 3, 5, 7, 9, 11}},
+		{3, 9, []int{3, 5, 7}},
+		{4, 5, []int{}},
+		{4, 6, []int{5}},
+		{12, 20, []int{}},
+		{9, 3, nil},
+	}
+	for _, test := range tests {
+		res := set.Range(test.lo, test.hi)
+		if len(res) != len(test.expected) || (len(res) > 0 && !reflect.DeepEqual(res, test.expected)) {
+			t.Errorf("Range(%v, %v) is %v, expected %v", test.lo, test.hi, res, test.expected)
+		}
+	}
+}
+
+func TestSortedSetNearest(t *testing.T) {
+	if _, ok := NewSortedSet(nil).Nearest(5); ok {
+		t.Error("empty set found a nearest value")
+	}
+
+	set := NewSortedSet([]int{10, 20, 30})
+	tests := [][]int{
+		{-5, 10},
+		{10, 10},
+		{14, 10},
+		{15, 10},
+		{16, 20},
+		{25, 20},
+		{29, 30},
+		{1000, 30},
+	}
+	for _, test := range tests {
+		if res, ok := set.Nearest(test[0]); !ok || res != test[1] {
+			t.Errorf("Nearest(%v) is %v, expected %v", test[0], res, test[1])
+		}
+	}
+}
+
+func TestLoadSortedSet(t *testing.T) {
+	ints := random.GenerateIncreasingRandomIntegers(sortedCount)
+	if err := writeSortedText(sortedTextFile, ints); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(sortedTextFile)
+
+	set, err := LoadSortedSet(sortedTextFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(set.Values(), ints) {
+		t.Error("loaded set doesn't match the file")
+	}
+}
+
+// the benchmarks all look up the same mix of hits and misses
+func setBenchmarkData() (values, queries []int) {
+	values = random.GenerateIncreasingRandomIntegers(setSize)
+	queries = random.GenerateUniqueRandomIntegers(values[len(values)-1])
+	return values, queries
+}
+
+func BenchmarkSortedSetContains(b *testing.B) {
+	values, queries := setBenchmarkData()
+	set := NewSortedSet(values)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		set.Contains(queries[i%len(queries)])
+	}
+}
+
+func BenchmarkSortedSetContainsBatch(b *testing.B) {
+	values, queries := setBenchmarkData()
+	set := NewSortedSet(values)
+	b.ResetTimer()
+	for i := 0; i < b.N; i += 64 {
+		start := i % (len(queries) - 64)
+		set.ContainsBatch(queries[start : start+64])
+	}
+}
+
+// control: plain binary search over the sorted slice
+func BenchmarkBinarySearchContains(b *testing.B) {
+	values, queries := setBenchmarkData()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		v := queries[i%len(queries)]
+		j := sort.SearchInts(values, v)
+		_ = j < len(values) && values[j] == v
+	}
+}
+
+// control: a Go map
+func BenchmarkMapContains(b *testing.B) {
+	values, queries := setBenchmarkData()
+	set := make(map[int]struct{}, len(values))
+	for _, v := range values {
+		set[v] = struct{}{}
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = set[queries[i%len(queries)]]
+	}
+}
@@ -0,0 +1,70 @@
+package substring
+
+// Horspool is the Boyer-Moore-Horspool search. It compares the pattern right to
+// left, and on a mismatch skips ahead based on the text byte under the end of
+// the pattern. Bytes that aren't in the pattern at all skip the whole length,
+// so long patterns in varied text touch only a fraction of the bytes.
+type Horspool struct {
+	text string
+}
+
+// NewHorspool makes a Boyer-Moore-Horspool Searcher over text
+func NewHorspool(text string) *Horspool {
+	return &Horspool{text}
+}
+
+// shifts builds the bad character table: how far the pattern can slide when the
+// text byte under its last position is c
+func shifts(pattern string) (shift [256]int) {
+	for c := range shift {
+		shift[c] = len(pattern)
+	}
+	for i := 0; i < len(pattern)-1; i++ {
+		shift[pattern[i]] = len(pattern) - 1 - i
+	}
+	return shift
+}
+
+// Index returns the offset of the first occurrence of pattern, or -1
+func (s *Horspool) Index(pattern string) int {
+	found := -1
+	s.scan(pattern, func(i int) bool {
+		found = i
+		return false
+	})
+	return found
+}
+
+// IndexAll returns the offset of every occurrence of pattern
+func (s *Horspool) IndexAll(pattern string) (all []int) {
+	s.scan(pattern, func(i int) bool {
+		all = append(all, i)
+		return true
+	})
+	return all
+}
+
+// scan calls match with the offset of each occurrence until match returns false
+func (s *Horspool) scan(pattern string, match func(int) bool) {
+	m := len(pattern)
+	if m == 0 {
+		for i := 0; i <= len(s.text); i++ {
+			if !match(i) {
+				return
+			}
+		}
+		return
+	}
+
+	shift := shifts(pattern)
+	last := pattern[m-1]
+	for pos := 0; pos+m <= len(s.text); {
+		end := s.text[pos+m-1]
+		if end == last && s.text[pos:pos+m-1] == pattern[:m-1] {
+			if !match(pos) {
+				return
+			}
+		}
+		pos += shift[end]
+	}
+}
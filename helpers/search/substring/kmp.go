@@ -0,0 +1,76 @@
+package substring
+
+// KMP is the Knuth-Morris-Pratt search. It never moves backwards in the text:
+// when a match fails partway, the failure table says how much of the pattern
+// we've already matched, so the scan picks up from there.
+type KMP struct {
+	text string
+}
+
+// NewKMP makes a KMP Searcher over text
+func NewKMP(text string) *KMP {
+	return &KMP{text}
+}
+
+// failure builds the table where fail[i] is the length of the longest proper prefix
+// of pattern[:i+1] that's also a suffix of it
+func failure(pattern string) []int {
+	fail := make([]int, len(pattern))
+	for i, k := 1, 0; i < len(pattern); i++ {
+		for k > 0 && pattern[i] != pattern[k] {
+			k = fail[k-1]
+		}
+		if pattern[i] == pattern[k] {
+			k++
+		}
+		fail[i] = k
+	}
+	return fail
+}
+
+// Index returns the offset of the first occurrence of pattern, or -1
+func (s *KMP) Index(pattern string) int {
+	found := -1
+	s.scan(pattern, func(i int) bool {
+		found = i
+		return false
+	})
+	return found
+}
+
+// IndexAll returns the offset of every occurrence of pattern
+func (s *KMP) IndexAll(pattern string) (all []int) {
+	s.scan(pattern, func(i int) bool {
+		all = append(all, i)
+		return true
+	})
+	return all
+}
+
+// scan calls match with the offset of each occurrence until match returns false
+func (s *KMP) scan(pattern string, match func(int) bool) {
+	if len(pattern) == 0 {
+		for i := 0; i <= len(s.text); i++ {
+			if !match(i) {
+				return
+			}
+		}
+		return
+	}
+
+	fail := failure(pattern)
+	for i, k := 0, 0; i < len(s.text); i++ {
+		for k > 0 && s.text[i] != pattern[k] {
+			k = fail[k-1]
+		}
+		if s.text[i] == pattern[k] {
+			k++
+		}
+		if k == len(pattern) {
+			if !match(i - k + 1) {
+				return
+			}
+			k = fail[k-1]
+		}
+	}
+}
@@ -0,0 +1,50 @@
+/*
+Package substring finds patterns inside a text.
+
+Each Searcher is built over one text and answers any number of pattern queries.
+KMP and Horspool do their preparation on the pattern, so every query scans the
+whole text. SuffixArray does its preparation on the text up front, after which
+each query is a binary search, which wins when there are a lot of queries.
+*/
+package substring
+
+import (
+	"strings"
+)
+
+// Searcher finds patterns in the text it was built over
+type Searcher interface {
+	// Index returns the offset of the first occurrence of pattern, or -1
+	Index(pattern string) int
+	// IndexAll returns the offset of every occurrence of pattern, including
+	// overlapping ones, in increasing order
+	IndexAll(pattern string) []int
+}
+
+// Stdlib wraps strings.Index. It's here as the control for the benchmarks.
+type Stdlib struct {
+	text string
+}
+
+// NewStdlib makes a Searcher backed by the strings package
+func NewStdlib(text string) *Stdlib {
+	return &Stdlib{text}
+}
+
+// Index returns the offset of the first occurrence of pattern, or -1
+func (s *Stdlib) Index(pattern string) int {
+	return strings.Index(s.text, pattern)
+}
+
+// IndexAll returns the offset of every occurrence of pattern
+func (s *Stdlib) IndexAll(pattern string) (all []int) {
+	for start := 0; start <= len(s.text); {
+		i := strings.Index(s.text[start:], pattern)
+		if i < 0 {
+			break
+		}
+		all = append(all, start+i)
+		start += i + 1
+	}
+	return all
+}
@@ -0,0 +1,124 @@
+package substring
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// the searchers under test, built over a text
+var searchers = map[string]func(string) Searcher{
+	"Stdlib":      func(t string) Searcher { return NewStdlib(t) },
+	"KMP":         func(t string) Searcher { return NewKMP(t) },
+	"Horspool":    func(t string) Searcher { return NewHorspool(t) },
+	"SuffixArray": func(t string) Searcher { return NewSuffixArray(t) },
+}
+
+// bruteForce is the obviously correct answer to compare against
+func bruteForce(text, pattern string) (all []int) {
+	for i := 0; i+len(pattern) <= len(text); i++ {
+		if text[i:i+len(pattern)] == pattern {
+			all = append(all, i)
+		}
+	}
+	return all
+}
+
+func randomString(r *rand.Rand, n int, alphabet string) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = alphabet[r.Intn(len(alphabet))]
+	}
+	return string(b)
+}
+
+func TestSearchers(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	texts := []string{"", "a", "aaaaaaaa", "abracadabra", "mississippi"}
+	// small alphabets give plenty of overlapping and repeated matches
+	for i := 0; i < 50; i++ {
+		texts = append(texts, randomString(r, r.Intn(200), "ab"), randomString(r, r.Intn(200), "abcd"))
+	}
+
+	for _, text := range texts {
+		patterns := []string{"a", "aa", "abra", "issi", "zzz", text, text + "a"}
+		for i := 0; i < 20; i++ {
+			patterns = append(patterns, randomString(r, 1+r.Intn(5), "abcd"))
+		}
+
+		for name, build := range searchers {
+			s := build(text)
+			for _, p := range patterns {
+				expected := bruteForce(text, p)
+				all := s.IndexAll(p)
+				if !reflect.DeepEqual(all, expected) {
+					t.Fatalf("%v: IndexAll(%q) in %q is %v, expected %v", name, p, text, all, expected)
+				}
+
+				first := -1
+				if len(expected) > 0 {
+					first = expected[0]
+				}
+				if i := s.Index(p); i != first {
+					t.Fatalf("%v: Index(%q) in %q is %v, expected %v", name, p, text, i, first)
+				}
+			}
+		}
+	}
+}
+
+func TestEmptyPattern(t *testing.T) {
+	for name, build := range searchers {
+		s := build("abc")
+		if i := s.Index(""); i != 0 {
+			t.Errorf("%v: empty pattern found at %v", name, i)
+		}
+		if all := s.IndexAll(""); !reflect.DeepEqual(all, []int{0, 1, 2, 3}) {
+			t.Errorf("%v: empty pattern found at %v", name, all)
+		}
+	}
+}
+
+func TestSuffixArrayOrder(t *testing.T) {
+	// the classic example
+	sa := NewSuffixArray("banana")
+	if expected := []int{5, 3, 1, 0, 4, 2}; !reflect.DeepEqual(sa.sa, expected) {
+		t.Errorf("suffix array is %v, expected %v", sa.sa, expected)
+	}
+	if c := sa.Count("ana"); c != 2 {
+		t.Errorf("ana occurs %v times, expected 2", c)
+	}
+
+	many := sa.IndexAllMany([]string{"a", "na", "x"})
+	if expected := [][]int{{1, 3, 5}, {2, 4}, nil}; !reflect.DeepEqual(many, expected) {
+		t.Errorf("IndexAllMany is %v, expected %v", many, expected)
+	}
+}
+
+// logText is a pile of fake log lines with the needle near the end
+var logText = strings.Repeat("2014-06-01 12:00:00 INFO request served in 3ms path=/index.html\n", 2000) +
+	"2014-06-01 12:00:01 ERROR connection reset by peer\n"
+
+var logPatterns = []string{"ERROR connection reset", "path=/index.html", "served in 3ms", "WARN"}
+
+// the benchmarks search for the same patterns so the numbers line up with strings.Index
+func benchmarkSearcher(b *testing.B, build func(string) Searcher) {
+	s := build(logText)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Index(logPatterns[i%len(logPatterns)])
+	}
+}
+
+func BenchmarkStdlib(b *testing.B)      { benchmarkSearcher(b, searchers["Stdlib"]) }
+func BenchmarkKMP(b *testing.B)         { benchmarkSearcher(b, searchers["KMP"]) }
+func BenchmarkHorspool(b *testing.B)    { benchmarkSearcher(b, searchers["Horspool"]) }
+func BenchmarkSuffixArray(b *testing.B) { benchmarkSearcher(b, searchers["SuffixArray"]) }
+
+func BenchmarkSuffixArrayBuild(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		NewSuffixArray(logText)
+	}
+}
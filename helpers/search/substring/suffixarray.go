@@ -0,0 +1,135 @@
+package substring
+
+import (
+	"sort"
+	"strings"
+)
+
+// SuffixArray holds the start offset of every suffix of the text, sorted so the
+// suffixes are in lexicographic order. Every occurrence of a pattern is the start
+// of a suffix that has the pattern as a prefix, and those suffixes sit next to each
+// other in the array, so a query is two binary searches.
+//
+// Building it is O(n log^2 n), so it only pays off when there are enough queries.
+type SuffixArray struct {
+	text string
+	sa   []int
+}
+
+// NewSuffixArray sorts the suffixes of text by prefix doubling: sort by the first
+// byte, then use those ranks to sort by the first 2 bytes, then 4, and so on until
+// every suffix has its own rank
+func NewSuffixArray(text string) *SuffixArray {
+	n := len(text)
+	sa := make([]int, n)
+	rank := make([]int, n)
+	next := make([]int, n)
+	for i := range sa {
+		sa[i] = i
+		rank[i] = int(text[i])
+	}
+
+	for k := 1; ; k <<= 1 {
+		// the rank of the half starting k bytes in, or -1 past the end
+		second := func(i int) int {
+			if i+k < n {
+				return rank[i+k]
+			}
+			return -1
+		}
+		sort.Slice(sa, func(a, b int) bool {
+			i, j := sa[a], sa[b]
+			if rank[i] != rank[j] {
+				return rank[i] < rank[j]
+			}
+			return second(i) < second(j)
+		})
+
+		if n == 0 {
+			break
+		}
+		next[sa[0]] = 0
+		for i := 1; i < n; i++ {
+			prev, cur := sa[i-1], sa[i]
+			next[cur] = next[prev]
+			if rank[prev] != rank[cur] || second(prev) != second(cur) {
+				next[cur]++
+			}
+		}
+		rank, next = next, rank
+
+		// all ranks distinct means the order is final
+		if rank[sa[n-1]] == n-1 {
+			break
+		}
+	}
+
+	return &SuffixArray{text: text, sa: sa}
+}
+
+// bounds returns the range of sa whose suffixes start with pattern
+func (s *SuffixArray) bounds(pattern string) (lo, hi int) {
+	lo = sort.Search(len(s.sa), func(i int) bool {
+		return s.text[s.sa[i]:] >= pattern
+	})
+	hi = lo + sort.Search(len(s.sa)-lo, func(i int) bool {
+		return !strings.HasPrefix(s.text[s.sa[lo+i]:], pattern)
+	})
+	return lo, hi
+}
+
+// Count returns the number of occurrences of pattern without collecting them
+func (s *SuffixArray) Count(pattern string) int {
+	if pattern == "" {
+		return len(s.text) + 1
+	}
+	lo, hi := s.bounds(pattern)
+	return hi - lo
+}
+
+// Index returns the offset of the first occurrence of pattern, or -1
+func (s *SuffixArray) Index(pattern string) int {
+	if pattern == "" {
+		return 0
+	}
+	lo, hi := s.bounds(pattern)
+	if lo == hi {
+		return -1
+	}
+	first := s.sa[lo]
+	for _, i := range s.sa[lo+1 : hi] {
+		if i < first {
+			first = i
+		}
+	}
+	return first
+}
+
+// IndexAll returns the offset of every occurrence of pattern
+func (s *SuffixArray) IndexAll(pattern string) []int {
+	if pattern == "" {
+		all := make([]int, len(s.text)+1)
+		for i := range all {
+			all[i] = i
+		}
+		return all
+	}
+	lo, hi := s.bounds(pattern)
+	if lo == hi {
+		return nil
+	}
+	all := make([]int, hi-lo)
+	copy(all, s.sa[lo:hi])
+	sort.Ints(all)
+	return all
+}
+
+// IndexAllMany runs IndexAll for a batch of patterns. This is the case the suffix
+// array is built for: one pass to prepare the text, then cheap queries.
+func (s *SuffixArray) IndexAllMany(patterns []string) [][]int {
+	results := make([][]int, len(patterns))
+	for i, p := range patterns {
+		results[i] = s.IndexAll(p)
+	}
+	return results
+}
@@ -0,0 +1,83 @@
+// Command intconvert converts integer files between the formats used around the repo:
+// newline-delimited text (the bitmap sorts), raw big-endian uint32s (search and
+// MakeBinaryFile), headered record files, and varint-delta streams.
+//
+//	intconvert -from text -to record -in /tmp/first_pearl_output.txt -out sorted.rec
+//
+// A summary of what went through is printed to stderr.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/Stantheman/pearls/helpers/binary"
+	"io"
+	"os"
+)
+
+func main() {
+	from := flag.String("from", "text", "input format: text, raw, record or delta")
+	to := flag.String("to", "raw", "output format: text, raw, record or delta")
+	in := flag.String("in", "-", "input file, - for stdin")
+	out := flag.String("out", "-", "output file, - for stdout (record output needs a real file)")
+	width := flag.Int("width", 32, "element width in bits for record output")
+	sorted := flag.Bool("sorted", false, "fail if the input isn't sorted")
+	flag.Parse()
+
+	if err := run(*from, *to, *in, *out, *width, *sorted); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(from, to, in, out string, width int, sorted bool) error {
+	c := binary.Converter{Width: width, RequireSorted: sorted}
+	var err error
+	if c.From, err = binary.ParseFormat(from); err != nil {
+		return err
+	}
+	if c.To, err = binary.ParseFormat(to); err != nil {
+		return err
+	}
+	// stdout is usually a pipe, and the record header is rewritten at the end
+	if c.To == binary.Record && out == "-" {
+		return fmt.Errorf("record output needs a file, use -out")
+	}
+
+	var input io.Reader = os.Stdin
+	if in != "-" {
+		fh, err := os.Open(in)
+		if err != nil {
+			return err
+		}
+		defer fh.Close()
+		input = fh
+	}
+
+	output := os.Stdout
+	if out != "-" {
+		output, err = os.Create(out)
+		if err != nil {
+			return err
+		}
+		defer output.Close()
+	}
+
+	stats, err := c.Convert(input, output)
+	if err != nil {
+		return err
+	}
+	// record output is only finished once the header rewrite hits the disk
+	if out != "-" {
+		if err := output.Close(); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "count: %v\n", stats.Count)
+	if stats.Count > 0 {
+		fmt.Fprintf(os.Stderr, "min: %v\nmax: %v\n", stats.Min, stats.Max)
+	}
+	fmt.Fprintf(os.Stderr, "duplicates: %v\nsorted: %v\n", stats.Duplicates, stats.Sorted)
+	return nil
+}